@@ -1,12 +1,14 @@
 package client
 
 import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
-	"strings"
 
 	paxos "github.com/marius-j-i/paxos/node"
 	"github.com/marius-j-i/paxos/util"
@@ -17,24 +19,86 @@ var (
 	errJsonValueType  = errors.New("unable to type assert Json-object `%v` into type `%s`")
 	errMissingJsonKey = errors.New("response json-body contained no keyword for `%s`")
 	errNotStatusOK    = errors.New("got [%d - %s], but wanted [%d - %s]")
+	errNotAccepted    = errors.New("value [%s] not accepted by [%s], which accepted [%v]")
 
 	/* Defines. */
-	contentTypeJson = `application/json`
-	protocol        = `http://`
-	stringType      = `string`
-	postPropose     = `/propose/%s`
+	contentTypeJson  = `application/json`
+	contentTypeBytes = `application/octet-stream`
+	protocol         = `http://` // https:// once TLS is set
+	stringType       = `string`
+	numberType       = `float64`
+	objectType       = `map[string]interface{}`
+	postPropose      = `/propose`
+	postFast         = `/fast/%d`
+	postMembers      = `/members/%s/%s`
 )
 
-/* Post value to proposer. */
-func Propose(host, port, value string) error {
+/* Clients of nodes, over TLS once set. */
+var (
+	httpClient = &http.Client{}
+	tlsConfig  *tls.Config // nil unless TLS is set
+)
+
+/* Set requests to nodes to use TLS, presenting certificate with key and
+ * verifying nodes against cluster CA, read from PEM-files.
+ * Nodes require clients to present a certificate issued by cluster CA. */
+func SetTLS(cacert, cert, key string) error {
+
+	creds, err := paxos.LoadCredentials(cacert, cert, key)
+	if err != nil {
+		return err
+	}
+	tlsConfig = creds.ClientConfig()
+	httpClient = &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	protocol = `https://`
+	return nil
+}
+
+/* Post value to proposer. Value is opaque bytes carried in the request body. */
+func Propose(host, port string, value []byte) error {
+	_, err := ProposeValue(host, port, value)
+	return err
+}
+
+/* Post value to proposer, and return proposal chosen for it; slots proposer
+ * filled with values of earlier proposals before it are listed as filled. */
+func ProposeValue(host, port string, value []byte) (*paxos.Proposal, error) {
+
+	/* Format POST url. */
+	addr := net.JoinHostPort(host, port)
+	url := protocol + addr + postPropose
+
+	/* POST to proposer. */
+	resp, err := httpClient.Post(url, contentTypeBytes, bytes.NewReader(value))
+	if err != nil {
+		return nil, err
+	}
+	/* Body open on success; close when done. */
+	defer resp.Body.Close()
+
+	/* Assert OK. */
+	if resp.StatusCode != http.StatusCreated {
+		return nil, unexpectedStatusCode(resp.StatusCode, http.StatusCreated)
+	}
+
+	/* Decode proposal chosen. */
+	p := &paxos.Proposal{}
+	if err := json.NewDecoder(resp.Body).Decode(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+/* Post membership change to proposer, setting role of member at address.
+ * Role is one of `proposer`, `accepter`, `learner`, or `none` to remove member. */
+func ChangeMember(host, port, member, role string) error {
 
 	/* Format POST url. */
 	addr := net.JoinHostPort(host, port)
-	proposeValue := fmt.Sprintf(postPropose, value)
-	url := protocol + addr + proposeValue
+	url := protocol + addr + fmt.Sprintf(postMembers, member, role)
 
 	/* POST to proposer. */
-	resp, err := http.Post(url, contentTypeJson, strings.NewReader(value))
+	resp, err := httpClient.Post(url, contentTypeJson, nil)
 	if err != nil {
 		return err
 	}
@@ -49,47 +113,235 @@ func Propose(host, port, value string) error {
 	return nil
 }
 
-/* Return acccepted value gotten from proposer. */
-func GetAccepted(host, port string) (string, int, error) {
+/* Post value directly to accepters for slot in a fast round.
+ * Return number of accepters that accepted value; the coordinator
+ * recovers slot if values sent by other clients collided. */
+func FastPropose(accepters []string, slot int, value []byte) (int, error) {
+
+	/* Go routine. */
+	results := make(chan error, len(accepters))
+	fast := func(addr string) {
+		url := protocol + addr + fmt.Sprintf(postFast, slot)
+
+		/* POST to accepter. */
+		resp, err := httpClient.Post(url, contentTypeBytes, bytes.NewReader(value))
+		if err != nil {
+			results <- err
+			return
+		}
+		/* Body open on success; close when done. */
+		defer resp.Body.Close()
+
+		/* Assert OK. */
+		if resp.StatusCode != http.StatusOK {
+			results <- unexpectedStatusCode(resp.StatusCode, http.StatusOK)
+			return
+		}
+		/* Accepter responds with value it accepted for slot. */
+		var body map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			results <- err
+		} else if v, ok := body[paxos.JsonKeyValue]; !ok {
+			results <- util.ErrorFormat(errMissingJsonKey, paxos.JsonKeyValue)
+		} else if accepted, err := parseValue(v); err != nil {
+			results <- err
+		} else if !bytes.Equal(accepted, value) {
+			results <- util.ErrorFormat(errNotAccepted, value, addr, accepted)
+		} else {
+			results <- nil
+		}
+	}
+	for _, addr := range accepters {
+		go fast(addr)
+	}
+	/* Count accepters that accepted value; report first error otherwise. */
+	accepted := 0
+	var first error
+	for range accepters {
+		if err := <-results; err == nil {
+			accepted++
+		} else if first == nil {
+			first = err
+		}
+	}
+	return accepted, first
+}
+
+/* Return acccepted value, slot index and ballot gotten from proposer for most recent slot. */
+func GetAccepted(host, port string) ([]byte, int, paxos.Ballot, error) {
 
 	/* Format GET url. */
 	addr := net.JoinHostPort(host, port)
 	url := protocol + addr + paxos.GetAccepted
 
+	/* Body responses are json-formatted. */
+	var body map[string]interface{}
+
 	/* GET accepted value. */
-	resp, err := http.Get(url)
+	if err := getJson(url, &body); err != nil {
+		return nil, -1, paxos.Ballot{}, err
+	}
+	return parseAccepted(body)
+}
+
+/* Return acccepted value and ballot gotten from proposer for slot in log. */
+func GetAcceptedSlot(host, port string, slot int) ([]byte, paxos.Ballot, error) {
+
+	/* Format GET url. */
+	addr := net.JoinHostPort(host, port)
+	url := fmt.Sprintf("%s%s%s/%d", protocol, addr, paxos.GetAccepted, slot)
+
+	/* Body responses are json-formatted. */
+	var body map[string]interface{}
+
+	/* GET accepted value. */
+	if err := getJson(url, &body); err != nil {
+		return nil, paxos.Ballot{}, err
+	}
+	v, _, b, err := parseAccepted(body)
+	return v, b, err
+}
+
+/* Return acccepted values gotten from proposer for slots within [from, to],
+ * mapped by slot index. */
+func GetAcceptedRange(host, port string, from, to int) (map[int][]byte, error) {
+
+	/* Format GET url. */
+	addr := net.JoinHostPort(host, port)
+	url := fmt.Sprintf("%s%s%s/%d/%d", protocol, addr, paxos.GetAccepted, from, to)
+
+	/* Body responses are json-formatted. */
+	var body map[string][]map[string]interface{}
+
+	/* GET accepted values. */
+	if err := getJson(url, &body); err != nil {
+		return nil, err
+	}
+
+	/* Put values into map from:
+	 * { log : [ entry1, entry2, ... entryN ] } */
+	entries, ok := body[paxos.JsonKeyLog]
+	if !ok {
+		return nil, util.ErrorFormat(errMissingJsonKey, paxos.JsonKeyLog)
+	}
+	log := make(map[int][]byte, len(entries))
+	for i := range entries {
+		v, slot, _, err := parseAccepted(entries[i])
+		if err != nil {
+			return nil, err
+		}
+		log[slot] = v
+	}
+	return log, nil
+}
+
+/* Return chosen value, slot index and ballot gotten from learner for most
+ * recent slot it learned. */
+func GetChosenLatest(host, port string) ([]byte, int, paxos.Ballot, error) {
+
+	/* Format GET url. */
+	addr := net.JoinHostPort(host, port)
+	url := protocol + addr + paxos.GetChosen
+
+	/* Body responses are json-formatted. */
+	var body map[string]interface{}
+
+	/* GET chosen value. */
+	if err := getJson(url, &body); err != nil {
+		return nil, -1, paxos.Ballot{}, err
+	}
+	v, b, err := parseChosen(body)
 	if err != nil {
-		return "", -1, err
+		return nil, -1, paxos.Ballot{}, err
+	}
+	s, ok := body[paxos.JsonKeySlot].(float64)
+	if !ok {
+		return nil, -1, paxos.Ballot{}, util.ErrorFormat(errJsonValueType, body[paxos.JsonKeySlot], numberType)
+	}
+	return v, int(s), b, nil
+}
+
+/* Return chosen value and ballot gotten from learner for slot in log. */
+func GetChosen(host, port string, slot int) ([]byte, paxos.Ballot, error) {
+
+	/* Format GET url. */
+	addr := net.JoinHostPort(host, port)
+	url := fmt.Sprintf("%s%s%s/%d", protocol, addr, paxos.GetChosen, slot)
+
+	/* Body responses are json-formatted. */
+	var body map[string]interface{}
+
+	/* GET chosen value. */
+	if err := getJson(url, &body); err != nil {
+		return nil, paxos.Ballot{}, err
+	}
+	return parseChosen(body)
+}
+
+/* Return chosen value and ballot from slot-entry of learner. */
+func parseChosen(body map[string]interface{}) ([]byte, paxos.Ballot, error) {
+
+	/* Extract chosen value. */
+	if v, ok := body[paxos.JsonKeyChosen]; !ok {
+		return nil, paxos.Ballot{}, util.ErrorFormat(errMissingJsonKey, paxos.JsonKeyChosen)
+
+	} else if chosen, err := parseValue(v); err != nil {
+		return nil, paxos.Ballot{}, err
+
+		/* Extract proposal ballot. */
+	} else if p, ok := body[paxos.JsonKeyProposal]; !ok {
+		return nil, paxos.Ballot{}, util.ErrorFormat(errMissingJsonKey, paxos.JsonKeyProposal)
+
+	} else if ballot, err := parseBallot(p); err != nil {
+		return nil, paxos.Ballot{}, err
+
+	} else {
+		return chosen, ballot, nil
+	}
+}
+
+/* GET url and decode json-body into argument. */
+func getJson(url string, body interface{}) error {
+
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return err
 	}
 	/* Body open on success; close when done. */
 	defer resp.Body.Close()
 
 	/* Assert OK. */
 	if resp.StatusCode != http.StatusOK {
-		return "", -1, unexpectedStatusCode(resp.StatusCode, http.StatusOK)
+		return unexpectedStatusCode(resp.StatusCode, http.StatusOK)
 	}
 
-	/* Body responses are json-formatted. */
-	var body map[string]interface{}
+	/* Decode body. */
+	return json.NewDecoder(resp.Body).Decode(body)
+}
 
-	/* Decode body into map. */
-	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
-		return "", -1, err
-	}
+/* Return accepted value, slot index and ballot from slot-entry. */
+func parseAccepted(body map[string]interface{}) ([]byte, int, paxos.Ballot, error) {
 
 	/* Extract accepted value. */
 	if v, ok := body[paxos.JsonKeyAccepted]; !ok {
-		return "", -1, util.ErrorFormat(errMissingJsonKey, paxos.JsonKeyAccepted)
+		return nil, -1, paxos.Ballot{}, util.ErrorFormat(errMissingJsonKey, paxos.JsonKeyAccepted)
+
+	} else if accepted, err := parseValue(v); err != nil {
+		return nil, -1, paxos.Ballot{}, err
 
-	} else if accepted, ok := v.(string); !ok {
-		return "", -1, util.ErrorFormat(errJsonValueType, v, stringType)
+		/* Extract slot index. */
+	} else if s, ok := body[paxos.JsonKeySlot]; !ok {
+		return nil, -1, paxos.Ballot{}, util.ErrorFormat(errMissingJsonKey, paxos.JsonKeySlot)
 
-		/* Extract proposal number. */
+	} else if slot, ok := s.(float64); !ok {
+		return nil, -1, paxos.Ballot{}, util.ErrorFormat(errJsonValueType, s, numberType)
+
+		/* Extract proposal ballot. */
 	} else if p, ok := body[paxos.JsonKeyProposal]; !ok {
-		return "", -1, util.ErrorFormat(errMissingJsonKey, paxos.JsonKeyProposal)
+		return nil, -1, paxos.Ballot{}, util.ErrorFormat(errMissingJsonKey, paxos.JsonKeyProposal)
 
-	} else if proposal, ok := p.(float64); !ok {
-		return "", -1, util.ErrorFormat(errJsonValueType, p, stringType)
+	} else if ballot, err := parseBallot(p); err != nil {
+		return nil, -1, paxos.Ballot{}, err
 
 		/* Omit prepare statement for now.
 		} else if p, ok := body[paxos.JsonKeyPrepare]; !ok {
@@ -100,7 +352,40 @@ func GetAccepted(host, port string) (string, int, error) {
 		*/
 
 	} else {
-		return accepted, int(proposal), nil
+		return accepted, int(slot), ballot, nil
+	}
+}
+
+/* Return opaque value from json-string, which encodes bytes in base64.
+ * An empty slot has a null value. */
+func parseValue(v interface{}) ([]byte, error) {
+
+	if v == nil {
+		return nil, nil
+
+	} else if s, ok := v.(string); !ok {
+		return nil, util.ErrorFormat(errJsonValueType, v, stringType)
+
+	} else {
+		return base64.StdEncoding.DecodeString(s)
+	}
+}
+
+/* Return ballot from json-object:
+ * { round : <int>, id : <int> } */
+func parseBallot(v interface{}) (paxos.Ballot, error) {
+
+	if b, ok := v.(map[string]interface{}); !ok {
+		return paxos.Ballot{}, util.ErrorFormat(errJsonValueType, v, objectType)
+
+	} else if round, ok := b[paxos.JsonKeyRound].(float64); !ok {
+		return paxos.Ballot{}, util.ErrorFormat(errJsonValueType, b[paxos.JsonKeyRound], numberType)
+
+	} else if id, ok := b[paxos.JsonKeyID].(float64); !ok {
+		return paxos.Ballot{}, util.ErrorFormat(errJsonValueType, b[paxos.JsonKeyID], numberType)
+
+	} else {
+		return paxos.Ballot{Round: int(round), ID: int(id)}, nil
 	}
 }
 
@@ -112,7 +397,7 @@ func GetAccepters(host, port string) ([]string, error) {
 	url := protocol + addr + paxos.GetAccepters
 
 	/* Get to proposer. */
-	resp, err := http.Get(url)
+	resp, err := httpClient.Get(url)
 	if err != nil {
 		return nil, err
 	}
@@ -159,7 +444,7 @@ func GetLearners(host, port string) ([]string, error) {
 	url := protocol + addr + paxos.GetLearners
 
 	/* Get to proposer. */
-	resp, err := http.Get(url)
+	resp, err := httpClient.Get(url)
 	if err != nil {
 		return nil, err
 	}
@@ -199,9 +484,70 @@ func GetLearners(host, port string) ([]string, error) {
 	}
 }
 
+/* Error of a node responding with another status code than expected.
+ * Callers tell rejected or unavailable requests apart by its code. */
+type StatusError struct {
+	Code     int // status code node responded with
+	Expected int // status code of a successful request
+}
+
+func (e *StatusError) Error() string {
+	recieved := http.StatusText(e.Code)
+	expected := http.StatusText(e.Expected)
+	return util.ErrorFormat(errNotStatusOK, e.Code, recieved, e.Expected, expected).Error()
+}
+
+/* Get to node and return nil if it responds alive. */
+func GetAlive(host, port string) error {
+
+	/* Format url. */
+	addr := net.JoinHostPort(host, port)
+	url := protocol + addr + paxos.GetAlive
+
+	/* Get to node. */
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	/* Body open on success; close when done. */
+	defer resp.Body.Close()
+
+	/* Assert OK. */
+	if resp.StatusCode != http.StatusOK {
+		return unexpectedStatusCode(resp.StatusCode, http.StatusOK)
+	}
+	return nil
+}
+
 /* Return formatted error from http codes. */
 func unexpectedStatusCode(recv, expt int) error {
-	recieved := http.StatusText(recv)
-	expected := http.StatusText(expt)
-	return util.ErrorFormat(errNotStatusOK, recv, recieved, expt, expected)
+	return &StatusError{Code: recv, Expected: expt}
+}
+
+/* Get to node and return its status. */
+func GetStatus(host, port string) (*paxos.Status, error) {
+
+	/* Format url. */
+	addr := net.JoinHostPort(host, port)
+	url := protocol + addr + paxos.GetStatus
+
+	s := &paxos.Status{}
+	if err := getJson(url, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+/* Get to node and return its status and internals. */
+func GetState(host, port string) (*paxos.State, error) {
+
+	/* Format url. */
+	addr := net.JoinHostPort(host, port)
+	url := protocol + addr + paxos.GetDebugState
+
+	s := &paxos.State{}
+	if err := getJson(url, s); err != nil {
+		return nil, err
+	}
+	return s, nil
 }
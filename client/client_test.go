@@ -1,10 +1,24 @@
 package client
 
 import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"net"
+	"net/http"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -23,20 +37,26 @@ var (
 	accepters   = 5
 	learners    = 4
 	host        = `localhost`
-	port, other = `8080`, `8081`
-	startport   = 8080
+	port, other = ``, `` // ports of proposers, bound when network starts
 
 	/* Proposer values. */
-	value, valueTwo = `value-one`, fmt.Sprintf("%s-%s", value, `two`)
+	value, valueTwo = []byte("value one\n"), []byte("value, two!\n")
 	acceptWindow    = 200 * time.Millisecond
 )
 
 func TestMain(m *testing.M) {
 
-	nodes, err := paxos.NewNetwork(proposers, accepters, learners)
+	/* Proposers are served on free ports. */
+	nodes, err := paxos.NewHttpNetwork(proposers, accepters, learners)
 	if err != nil {
 		log.Fatal(err)
 	}
+	P, _, _ := nodes.Members()
+	if host, port, err = net.SplitHostPort(P[0].Addr()); err != nil {
+		log.Fatal(err)
+	} else if _, other, err = net.SplitHostPort(P[1].Addr()); err != nil {
+		log.Fatal(err)
+	}
 	code := m.Run()
 	nodes.Close()
 	os.Exit(code)
@@ -51,9 +71,34 @@ func TestPropose(t *testing.T) {
 	}
 }
 
+func TestProposeValue(t *testing.T) {
+
+	p, err := ProposeValue(host, port, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	/* Proposal reports the value accepted for its slot. */
+	if v, b, err := GetAcceptedSlot(host, port, p.Slot); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(v, p.Value) {
+		t.Errorf("proposal chose [%s] for slot [%d], but [%s] was accepted", p.Value, p.Slot, v)
+	} else if b != p.Ballot {
+		t.Errorf("proposal chose ballot [%s] for slot [%d], but [%s] was accepted", p.Ballot, p.Slot, b)
+	}
+
+	/* Empty values are refused with their status code. */
+	_, err = ProposeValue(host, port, nil)
+	var status *StatusError
+	if !errors.As(err, &status) {
+		t.Fatalf("expected status error proposing empty value, got [%v]", err)
+	} else if status.Code != http.StatusBadRequest {
+		t.Errorf("expected status [%d] proposing empty value, got [%d]", http.StatusBadRequest, status.Code)
+	}
+}
+
 func TestGetAccepted(t *testing.T) {
 
-	if _, _, err := GetAccepted(host, port); err != nil {
+	if _, _, _, err := GetAccepted(host, port); err != nil {
 		t.Error(err)
 	} /* Can not make assumptions about value or proposal. */
 }
@@ -81,10 +126,10 @@ func TestProposeThenGetAccepted(t *testing.T) {
 	}
 
 	/* ... then fetch and assert accepted. */
-	if accepted, _, err := GetAccepted(host, port); err != nil {
+	if accepted, _, _, err := GetAccepted(host, port); err != nil {
 		t.Error(err)
 
-	} else if accepted != value {
+	} else if !bytes.Equal(accepted, value) {
 		err = util.ErrorFormat(errValueNotAccepted, value, accepted, acceptWindow)
 		t.Error(err)
 	}
@@ -92,8 +137,8 @@ func TestProposeThenGetAccepted(t *testing.T) {
 
 func TestGetAcceptedThenProposeThenGetAccepted(t *testing.T) {
 
-	/* Get existing accepted proposal number. */
-	_, n1, err := GetAccepted(host, port)
+	/* Get existing most recent slot. */
+	_, n1, _, err := GetAccepted(host, port)
 	if err != nil {
 		t.Error(err.Error())
 		t.FailNow()
@@ -120,13 +165,96 @@ func TestGetAcceptedThenProposeThenGetAccepted(t *testing.T) {
 	}
 
 	/* ... then fetch and assert accepted. */
-	if _, n2, err := GetAccepted(host, port); err != nil {
+	if _, n2, _, err := GetAccepted(host, port); err != nil {
 		t.Error(err)
 
 	} else if n1 != n2-1 {
 		err = util.ErrorFormat(errProposalNotAccepted, n2, n1, acceptWindow)
-		t.Errorf(err.Error())
+		t.Error(err)
+
+	}
+}
 
+func TestProposeThenGetAcceptedRange(t *testing.T) {
+
+	if err := Propose(host, port, valueTwo); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	/* Proposed value should be appended to log of proposer. */
+	log, err := GetAcceptedRange(host, port, 1, math.MaxInt32)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	last := 0
+	for slot := range log {
+		if slot > last {
+			last = slot
+		}
+	}
+	if !bytes.Equal(log[last], valueTwo) {
+		t.Error(util.ErrorFormat(errValueNotAccepted, valueTwo, log[last], 0))
+	} else if v, _, err := GetAcceptedSlot(host, port, last); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(v, valueTwo) {
+		t.Error(util.ErrorFormat(errValueNotAccepted, valueTwo, v, 0))
+	}
+}
+
+func TestGetAlive(t *testing.T) {
+
+	if err := GetAlive(host, port); err != nil {
+		t.Error(err)
+	}
+	/* A port nothing listens on is not alive. */
+	l, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, closed, _ := net.SplitHostPort(l.Addr().String())
+	l.Close()
+	if err := GetAlive(host, closed); err == nil {
+		t.Errorf("expected error from [%s:%s] nothing listens on", host, closed)
+	}
+}
+
+func TestGetStatus(t *testing.T) {
+
+	if err := Propose(host, port, value); err != nil {
+		t.Fatal(err)
+	}
+	s, err := GetStatus(host, port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Role != "proposer" || s.Addr != net.JoinHostPort(host, port) {
+		t.Errorf("expected proposer at [%s:%s], got [%s] at [%s]", host, port, s.Role, s.Addr)
+	}
+	if s.Accepters != accepters || len(s.Peers) != proposers+accepters+learners-1 {
+		t.Errorf("expected [%d] accepters of [%d] peers, got [%d] of [%d]",
+			accepters, proposers+accepters+learners-1, s.Accepters, len(s.Peers))
+	}
+	/* Proposer heard from peers as value was chosen, or forwarded, and timed their replies. */
+	seen := 0
+	for addr, peer := range s.Peers {
+		if peer.LastSeen.IsZero() {
+			continue
+		} else if peer.RTT <= 0 {
+			t.Errorf("peer [%s] was seen without a round-trip time", addr)
+		}
+		seen++
+	}
+	if seen == 0 {
+		t.Errorf("proposer [%s] saw none of its peers", s.Addr)
+	}
+	state, err := GetState(host, port)
+	if err != nil {
+		t.Fatal(err)
+	} else if state.Addr != s.Addr || state.InFlight != len(state.Proposals) {
+		t.Errorf("expected state of [%s] to list its [%d] proposals in progress, got [%s] listing [%d]",
+			s.Addr, state.InFlight, state.Addr, len(state.Proposals))
 	}
 }
 
@@ -140,7 +268,10 @@ func TestGetAccepters(t *testing.T) {
 
 	/* Assert addresses are resolvable. */
 	for i := range a {
-		if _, err := net.LookupHost(a[i]); err != nil {
+		if h, _, err := net.SplitHostPort(a[i]); err != nil {
+			t.Error(err)
+			t.FailNow()
+		} else if _, err := net.LookupHost(h); err != nil {
 			t.Error(err)
 			t.FailNow()
 		}
@@ -157,13 +288,224 @@ func TestGetLearners(t *testing.T) {
 
 	/* Assert addresses are resolvable. */
 	for i := range l {
-		if _, err := net.LookupHost(l[i]); err != nil {
+		if h, _, err := net.SplitHostPort(l[i]); err != nil {
+			t.Error(err)
+			t.FailNow()
+		} else if _, err := net.LookupHost(h); err != nil {
 			t.Error(err)
 			t.FailNow()
 		}
 	}
 }
 
+func TestGrpc(t *testing.T) {
+
+	/* Nodes of this network message each other over gRPC. */
+	nodes, err := paxos.NewGrpcNetwork(2, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nodes.Close()
+
+	P, A, _ := nodes.Members()
+	h, p, err := net.SplitHostPort(P[0].Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewGrpcClient(h, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	/* Propose single value, ... */
+	first, err := c.Propose(ctx, value)
+	if err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(first.Value, value) {
+		t.Errorf("proposed [%s], but [%s] was chosen", value, first.Value)
+	}
+	/* ... then a stream of values, chosen for consecutive slots. */
+	values := [][]byte{[]byte("one"), []byte("two"), []byte("three")}
+	proposals, err := c.ProposeStream(ctx, values)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(proposals) != len(values) {
+		t.Fatalf("streamed [%d] values, but got [%d] proposals", len(values), len(proposals))
+	}
+	for i, p := range proposals {
+		if p.Slot != first.Slot+1+i || !bytes.Equal(p.Value, values[i]) {
+			t.Errorf("value [%s] chosen for slot [%d], but wanted [%s] for slot [%d]",
+				p.Value, p.Slot, values[i], first.Slot+1+i)
+		}
+	}
+	/* Accepted values are streamed back. */
+	log, err := c.GetAccepted(ctx, first.Slot, first.Slot+len(values))
+	if err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(log[first.Slot], value) {
+		t.Errorf("slot [%d] accepted [%s], but wanted [%s]", first.Slot, log[first.Slot], value)
+	}
+	/* Proposals to other proposer are forwarded to leader. */
+	h, p, err = net.SplitHostPort(P[1].Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	forwarder, err := NewGrpcClient(h, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer forwarder.Close()
+	if proposal, err := forwarder.Propose(ctx, valueTwo); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(proposal.Value, valueTwo) {
+		t.Errorf("proposed [%s], but [%s] was chosen", valueTwo, proposal.Value)
+	}
+	/* Members include node itself. */
+	members, err := c.Members(ctx)
+	if err != nil {
+		t.Fatal(err)
+	} else if members[P[0].Addr()] != "proposer" || members[A[0].Addr()] != "accepter" {
+		t.Errorf("unexpected members [%v]", members)
+	}
+}
+
+func TestTLS(t *testing.T) {
+
+	/* Issue cluster CA, and certificates of nodes and client. */
+	dir := t.TempDir()
+	path := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+	ca, caKey := issue(t, path("ca"), nil, nil, 1)
+	issue(t, path("node"), ca, caKey, 2, x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth)
+	issue(t, path("client"), ca, caKey, 3, x509.ExtKeyUsageClientAuth)
+
+	creds, err := paxos.LoadCredentials(path("ca.pem"), path("node.pem"), path("node-key.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := paxos.NewHttpNetworkTLS(1, 3, 1, creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nodes.Close()
+	P, _, _ := nodes.Members()
+	h, p, err := net.SplitHostPort(P[0].Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	/* Restore plain HTTP for other tests. */
+	defer func(c *http.Client, cfg *tls.Config, scheme string) {
+		httpClient, tlsConfig, protocol = c, cfg, scheme
+	}(httpClient, tlsConfig, protocol)
+
+	/* Clients without a certificate issued by cluster CA are refused. */
+	if err := Propose(h, p, value); err == nil {
+		t.Error("proposal over plain HTTP succeeded")
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	httpClient = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	protocol = `https://`
+	if err := Propose(h, p, value); err == nil {
+		t.Error("proposal without client certificate succeeded")
+	}
+
+	/* Clients with a certificate are served. */
+	if err := SetTLS(path("ca.pem"), path("client.pem"), path("client-key.pem")); err != nil {
+		t.Fatal(err)
+	} else if err := Propose(h, p, value); err != nil {
+		t.Fatal(err)
+	}
+
+	/* Rotated certificate of node is presented on new connections. */
+	issue(t, path("node"), ca, caKey, 4, x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth)
+	later := time.Now().Add(time.Second)
+	for _, name := range []string{"node.pem", "node-key.pem"} {
+		if err := os.Chtimes(path(name), later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	conn, err := tls.Dial("tcp", P[0].Addr(), tlsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial := conn.ConnectionState().PeerCertificates[0].SerialNumber
+	conn.Close()
+	if serial.Int64() != 4 {
+		t.Errorf("node presented certificate [#%d], but wanted rotated certificate [#%d]", serial, 4)
+	}
+	/* Nodes still reach each other. */
+	if err := Propose(h, p, valueTwo); err != nil {
+		t.Fatal(err)
+	}
+
+	/* gRPC is served with TLS as well. */
+	grpcNodes, err := paxos.NewGrpcNetworkTLS(1, 3, 1, creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer grpcNodes.Close()
+	P, _, _ = grpcNodes.Members()
+	if h, p, err = net.SplitHostPort(P[0].Addr()); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewGrpcClient(h, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.Propose(context.Background(), value); err != nil {
+		t.Fatal(err)
+	}
+}
+
+/* Issue certificate with serial number and key usages, signed by ca, and write
+ * it with its key to PEM-files at path. Certificate is a CA if ca is nil. */
+func issue(t *testing.T, path string, ca *x509.Certificate, caKey crypto.Signer, serial int64, usages ...x509.ExtKeyUsage) (*x509.Certificate, crypto.Signer) {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: filepath.Base(path)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  usages,
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	if ca == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		ca, caKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, key.Public(), caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	} else if err := os.WriteFile(path+"-key.pem", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
 func TestTwoPropose(t *testing.T) {
 	t.Skip()
 
@@ -186,15 +528,15 @@ func TestTwoPropose(t *testing.T) {
 		t.FailNow()
 	}
 
-	if v1, n1, err := GetAccepted(host, port); err != nil {
+	if v1, _, n1, err := GetAccepted(host, port); err != nil {
 		t.Error(err)
-	} else if v2, n2, err := GetAccepted(host, other); err != nil {
+	} else if v2, _, n2, err := GetAccepted(host, other); err != nil {
 		t.Error(err)
-	} else if n1 >= n2 {
-		t.Errorf(`n1<n2 -> !true -> %d<%d: 1st proposal number should be less than 2nd`, n1, n2)
-	} else if v1 != valueTwo {
+	} else if !n1.Less(n2) {
+		t.Errorf(`n1<n2 -> !true -> %s<%s: 1st proposal ballot should be less than 2nd`, n1, n2)
+	} else if !bytes.Equal(v1, valueTwo) {
 		t.Errorf(`v1==valueTwo -> !true -> %s==%s: 1st proposer value should be 2nd value after 2nd proposal`, v1, valueTwo)
-	} else if v2 != valueTwo {
+	} else if !bytes.Equal(v2, valueTwo) {
 		t.Errorf(`v2==valueTwo -> !true -> %s==%s: 2nd proposer value should be 2nd value after 2nd proposal`, v2, valueTwo)
 	}
 }
@@ -202,7 +544,7 @@ func TestTwoPropose(t *testing.T) {
 func BenchmarkTxPerS(b *testing.B) {
 	b.Skip()
 
-	var proposal string
+	var proposal []byte
 	p := func(b *testing.B) {
 		if err := Propose(host, port, proposal); err != nil {
 			b.Error(err)
@@ -211,9 +553,9 @@ func BenchmarkTxPerS(b *testing.B) {
 	}
 
 	for n := 0; n < b.N; n++ {
-		proposal = fmt.Sprintf("Proposal-[%d]", n+1)
+		proposal = []byte(fmt.Sprintf("Proposal-[%d]", n+1))
 
-		if failed := b.Run(proposal, p); failed || b.Failed() {
+		if failed := b.Run(string(proposal), p); failed || b.Failed() {
 			b.FailNow()
 		}
 	}
@@ -0,0 +1,147 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+
+	paxos "github.com/marius-j-i/paxos/node"
+	"github.com/marius-j-i/paxos/paxospb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+/* Client of the gRPC service of a paxos node, backed by generated stubs.
+ * Nodes serve the gRPC service on the same port as the HTTP API.
+ */
+type GrpcClient struct {
+	conn  *grpc.ClientConn    // connection to node
+	paxos paxospb.PaxosClient // stub of gRPC service
+}
+
+/* Return client of gRPC service of node at host and port, over TLS if set.
+ * Connection is made on first call; call Close when done. */
+func NewGrpcClient(host, port string) (*GrpcClient, error) {
+
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	addr := net.JoinHostPort(host, port)
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	return &GrpcClient{conn: conn, paxos: paxospb.NewPaxosClient(conn)}, nil
+}
+
+/* Close connection to node. */
+func (c *GrpcClient) Close() error {
+	return c.conn.Close()
+}
+
+/* Propose value to proposer, and return proposal chosen. */
+func (c *GrpcClient) Propose(ctx context.Context, value []byte) (*paxos.Proposal, error) {
+
+	p, err := c.paxos.Propose(ctx, &paxospb.ProposeRequest{Value: value})
+	if err != nil {
+		return nil, err
+	}
+	return proposal(p), nil
+}
+
+/* Propose values to proposer in order over a single stream, and return
+ * proposals chosen in the same order. Stream ends on first value not chosen. */
+func (c *GrpcClient) ProposeStream(ctx context.Context, values [][]byte) ([]*paxos.Proposal, error) {
+
+	stream, err := c.paxos.ProposeStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	/* Send values, ... */
+	for _, v := range values {
+		if err := stream.Send(&paxospb.ProposeRequest{Value: v}); err != nil {
+			return nil, err
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	/* ... then receive proposals until proposer ends stream. */
+	proposals := make([]*paxos.Proposal, 0, len(values))
+	for {
+		p, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return proposals, nil
+		} else if err != nil {
+			return proposals, err
+		}
+		proposals = append(proposals, proposal(p))
+	}
+}
+
+/* Return acccepted values of node for slots within [from, to], mapped by slot index. */
+func (c *GrpcClient) GetAccepted(ctx context.Context, from, to int) (map[int][]byte, error) {
+
+	stream, err := c.paxos.GetAccepted(ctx, &paxospb.GetAcceptedRequest{From: int64(from), To: int64(to)})
+	if err != nil {
+		return nil, err
+	}
+	log := map[int][]byte{}
+	for {
+		entry, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return log, nil
+		} else if err != nil {
+			return nil, err
+		}
+		log[int(entry.GetSlot())] = entry.GetAccepted()
+	}
+}
+
+/* Return network members known to node, mapping address to role. */
+func (c *GrpcClient) Members(ctx context.Context) (map[string]string, error) {
+
+	resp, err := c.paxos.GetMembers(ctx, &paxospb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[string]string, len(resp.GetMembers()))
+	for _, m := range resp.GetMembers() {
+		members[m.GetAddr()] = m.GetRole()
+	}
+	return members, nil
+}
+
+/* Change role of member at address through proposer, and return proposal chosen.
+ * Role is one of `proposer`, `accepter`, `learner`, or `none` to remove member. */
+func (c *GrpcClient) ChangeMember(ctx context.Context, member, role string) (*paxos.Proposal, error) {
+
+	p, err := c.paxos.ChangeMember(ctx, &paxospb.ChangeRequest{Member: member, Role: role})
+	if err != nil {
+		return nil, err
+	}
+	return proposal(p), nil
+}
+
+/* Return proposal from message of gRPC service. */
+func proposal(p *paxospb.Proposal) *paxos.Proposal {
+	return &paxos.Proposal{
+		Slot:    int(p.GetSlot()),
+		Ballot:  paxos.Ballot{Round: int(p.GetBallot().GetRound()), ID: int(p.GetBallot().GetId())},
+		Value:   p.GetValue(),
+		Adopted: p.GetAdopted(),
+		Filled:  slots(p.GetFilled()),
+	}
+}
+
+/* Return slots of message of gRPC service. */
+func slots(pb []int64) []int {
+	s := make([]int, 0, len(pb))
+	for _, i := range pb {
+		s = append(s, int(i))
+	}
+	return s
+}
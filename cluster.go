@@ -0,0 +1,524 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/marius-j-i/paxos/client"
+	paxos "github.com/marius-j-i/paxos/node"
+	"github.com/marius-j-i/paxos/util"
+)
+
+var (
+	/* Errors. */
+	errClusterCommand = errors.New("unknown cluster command [%s]; expected up, down or logs")
+	errClusterUp      = errors.New("cluster already up in [%s]; stop it with `paxos cluster down -dir %s`")
+	errClusterDown    = errors.New("no cluster up in [%s]: %s")
+	errPaxosd         = errors.New("paxosd is neither next to paxos nor in PATH; build it with `go build ./cmd/paxosd`, or give -paxosd: %s")
+	errNodeExited     = errors.New("node [%s] exited before serving, with %s; see [%s]")
+	errNodeNotAlive   = errors.New("node [%s] not alive after %s; see [%s]")
+	errNodeKilled     = errors.New("node [%s] with pid [%d] did not stop within %s, and was killed")
+
+	/* Files of cluster, within its directory. */
+	clusterManifest = "manifest.json" // nodes started, with their process and log
+	clusterConfig   = "cluster.json"  // config file every node is started with
+	clusterLogs     = "logs"          // directory of node logs
+	clusterState    = "nodes"         // directory of node state
+
+	/* Intervals. */
+	pollInterval = 100 * time.Millisecond                // between checks of nodes, and their logs
+	stopTimeout  = paxos.SHUTDOWNTIMEOUT + 2*time.Second // time nodes are given to stop gracefully
+)
+
+/* Cluster of node processes started by `cluster up`, as written to its
+ * manifest. `cluster down` and `cluster logs` find nodes by the manifest.
+ */
+type manifest struct {
+	Config string         `json:"config"` // path of config file nodes share
+	Nodes  []*clusterNode `json:"nodes"`  // nodes started
+}
+
+/* Node process of a cluster. */
+type clusterNode struct {
+	Role string `json:"role"` // role of node
+	Addr string `json:"addr"` // address node listens on
+	PID  int    `json:"pid"`  // process id of node
+	Log  string `json:"log"`  // path of file node logs to
+}
+
+/* Config file nodes share, with the keys paxosd reads. */
+type clusterConfigFile struct {
+	Members   map[string]string `json:"members"`   // address mapping to role of every node
+	Transport string            `json:"transport"` // carries peer messages; http, or grpc
+	Storage   string            `json:"storage"`   // keeps state; file, bolt, or memory
+	Dir       string            `json:"dir"`       // directory of state files
+	Snapshot  int               `json:"snapshot"`  // slots applied between snapshots
+}
+
+/* paxos cluster up|down|logs */
+func cluster(arguments []string) error {
+	if len(arguments) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: paxos cluster up|down|logs [flags]")
+		os.Exit(exitUsage)
+	}
+	switch arguments[0] {
+	case "up":
+		return clusterUp(arguments[1:])
+	case "down":
+		return clusterDown(arguments[1:])
+	case "logs":
+		return clusterTail(arguments[1:])
+	}
+	return &codeError{code: exitUsage, err: util.ErrorFormat(errClusterCommand, arguments[0])}
+}
+
+/* paxos cluster up
+ * Start every node as a process of paxosd on a free port of host, write
+ * manifest, wait until nodes are alive, and tail their logs until interrupted.
+ * Nodes keep running once interrupted, until stopped by `cluster down`. */
+func clusterUp(arguments []string) error {
+	cmdarg, dir, host, paxosd := &CmdArg{}, "", "", ""
+	proposers, accepters, learners := 0, 0, 0
+	config := clusterConfigFile{Members: map[string]string{}}
+	wait, detach := time.Duration(0), false
+
+	args := newUsageFlagSet("cluster up", "")
+	args.StringVar(&dir, "dir", "cluster",
+		"Optional: Directory of cluster manifest, config, logs and node state")
+	args.IntVar(&proposers, "proposers", 1, "Optional: Number of proposers")
+	args.IntVar(&accepters, "accepters", 3, "Optional: Number of accepters")
+	args.IntVar(&learners, "learners", 1, "Optional: Number of learners")
+	args.StringVar(&host, "host", "localhost",
+		"Optional: Host nodes listen on, each on a free port")
+	args.StringVar(&config.Transport, "transport", "http",
+		"Optional: Transport of peer messages; http, or grpc")
+	args.StringVar(&config.Storage, "storage", "file",
+		"Optional: Storage of node state; file, bolt, or memory")
+	args.IntVar(&config.Snapshot, "snapshot", 0,
+		"Optional: Slots applied between snapshots; zero disables snapshots")
+	args.StringVar(&paxosd, "paxosd", "",
+		"Optional: Path to paxosd (default paxosd next to paxos, or in PATH)")
+	args.DurationVar(&wait, "wait", 10*time.Second,
+		"Optional: Time to wait for nodes to be alive before stopping them")
+	args.BoolVar(&detach, "detach", false,
+		"Optional: Exit once nodes are alive, instead of tailing their logs")
+	args.BoolVar(&cmdarg.json, "json", false,
+		"Optional: Write output as json instead of text")
+	args.Parse(arguments)
+
+	if proposers < 1 || accepters < 1 || learners < 0 || args.NArg() > 0 {
+		usage(args)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(dir, clusterManifest)); err == nil {
+		return util.ErrorFormat(errClusterUp, dir, dir)
+	}
+	if paxosd, err = lookPaxosd(paxosd); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, clusterLogs), os.ModePerm); err != nil {
+		return err
+	}
+
+	/* Roles in order of nodes in a Network; proposers, accepters, then learners. */
+	roles := []string{}
+	for _, r := range []struct {
+		role  string
+		count int
+	}{{"proposer", proposers}, {"accepter", accepters}, {"learner", learners}} {
+		for i := 0; i < r.count; i++ {
+			roles = append(roles, r.role)
+		}
+	}
+
+	m := &manifest{Config: filepath.Join(dir, clusterConfig)}
+	config.Dir = filepath.Join(dir, clusterState)
+	for _, role := range roles {
+		addr, err := freeAddr(host)
+		if err != nil {
+			return err
+		}
+		_, port, _ := net.SplitHostPort(addr)
+		m.Nodes = append(m.Nodes, &clusterNode{
+			Role: role,
+			Addr: addr,
+			Log:  filepath.Join(dir, clusterLogs, role+"-"+port+".log"),
+		})
+		config.Members[addr] = role
+	}
+	if err := writeJson(m.Config, &config); err != nil {
+		return err
+	}
+
+	/* Nodes started are stopped again if the cluster does not come up. */
+	exited := make(chan nodeExit, len(m.Nodes))
+	for _, n := range m.Nodes {
+		if err := startNode(paxosd, m.Config, n, exited); err != nil {
+			stopNodes(m, stopTimeout)
+			return err
+		}
+	}
+	if err := writeJson(filepath.Join(dir, clusterManifest), m); err != nil {
+		stopNodes(m, stopTimeout)
+		return err
+	}
+	if err := waitAlive(m.Nodes, wait, exited); err != nil {
+		stopNodes(m, stopTimeout)
+		os.Remove(filepath.Join(dir, clusterManifest))
+		return err
+	}
+
+	err = output(cmdarg, m, func(w io.Writer) {
+		for _, n := range m.Nodes {
+			fmt.Fprintf(w, "%s\t%s\tpid %d\t%s\n", n.Role, n.Addr, n.PID, n.Log)
+		}
+	})
+	if err != nil || detach {
+		return err
+	}
+	defer fmt.Fprintf(os.Stderr, "cluster keeps running; stop it with `paxos cluster down -dir %s`\n", dir)
+	return tailLogs(cmdarg, m)
+}
+
+/* paxos cluster down
+ * Stop every node of cluster gracefully, or kill those that do not stop in
+ * time, and remove manifest. */
+func clusterDown(arguments []string) error {
+	cmdarg, dir, timeout, clean := &CmdArg{}, "", time.Duration(0), false
+
+	args := newUsageFlagSet("cluster down", "")
+	args.StringVar(&dir, "dir", "cluster",
+		"Optional: Directory of cluster manifest")
+	args.DurationVar(&timeout, "timeout", stopTimeout,
+		"Optional: Time nodes are given to stop gracefully before they are killed")
+	args.BoolVar(&clean, "clean", false,
+		"Optional: Remove directory of cluster, with logs and node state, once stopped")
+	args.BoolVar(&cmdarg.json, "json", false,
+		"Optional: Write output as json instead of text")
+	args.Parse(arguments)
+
+	if args.NArg() > 0 {
+		usage(args)
+	}
+	m, err := readManifest(dir)
+	if err != nil {
+		return err
+	}
+	/* Entries whose pid no longer runs node are stale, and left alone;
+	 * the pid may since be taken by another process. */
+	stale := map[*clusterNode]bool{}
+	for _, n := range m.Nodes {
+		stale[n] = !m.running(n)
+	}
+	stopped := stopNodes(m, timeout)
+
+	/* Manifest is removed even if nodes were killed, so no later
+	 * `cluster down` signals their pids. */
+	if err := os.Remove(filepath.Join(dir, clusterManifest)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if clean {
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+	err = output(cmdarg, m, func(w io.Writer) {
+		for _, n := range m.Nodes {
+			state := "stopped"
+			if stale[n] {
+				state = "not running"
+			}
+			fmt.Fprintf(w, "%s %s\t%s\tpid %d\n", state, n.Role, n.Addr, n.PID)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return stopped
+}
+
+/* paxos cluster logs
+ * Tail logs of every node of cluster until interrupted. */
+func clusterTail(arguments []string) error {
+	cmdarg, dir := &CmdArg{}, ""
+
+	args := newUsageFlagSet("cluster logs", "")
+	args.StringVar(&dir, "dir", "cluster",
+		"Optional: Directory of cluster manifest")
+	args.BoolVar(&cmdarg.json, "json", false,
+		"Optional: Write log lines as json instead of text")
+	args.Parse(arguments)
+
+	if args.NArg() > 0 {
+		usage(args)
+	}
+	m, err := readManifest(dir)
+	if err != nil {
+		return err
+	}
+	return tailLogs(cmdarg, m)
+}
+
+/* Node process that exited, with error of its exit status. */
+type nodeExit struct {
+	node *clusterNode
+	err  error
+}
+
+/* Start node as process of paxosd with config file, logging to its log.
+ * Process is sent on exited once it exits. */
+func startNode(paxosd, config string, n *clusterNode, exited chan<- nodeExit) error {
+
+	f, err := os.OpenFile(n.Log, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(paxosd, "-config", config, "-addr", n.Addr)
+	cmd.Stdout, cmd.Stderr = f, f
+
+	/* Interrupts of terminal reach paxos only, not nodes. */
+	detachProcess(cmd)
+
+	if err := cmd.Start(); err != nil {
+		f.Close()
+		return err
+	}
+	n.PID = cmd.Process.Pid
+
+	go func() {
+		err := cmd.Wait()
+		f.Close()
+		exited <- nodeExit{node: n, err: err}
+	}()
+	return nil
+}
+
+/* Wait until every node is alive, or return error once wait has passed, or
+ * any node exited. */
+func waitAlive(nodes []*clusterNode, wait time.Duration, exited <-chan nodeExit) error {
+	deadline := time.Now().Add(wait)
+
+	for _, n := range nodes {
+		host, port, err := net.SplitHostPort(n.Addr)
+		if err != nil {
+			return err
+		}
+		for client.GetAlive(host, port) != nil {
+			if time.Now().After(deadline) {
+				return util.ErrorFormat(errNodeNotAlive, n.Addr, wait, n.Log)
+			}
+			select {
+			case e := <-exited:
+				status := "exit status 0"
+				if e.err != nil {
+					status = e.err.Error()
+				}
+				return util.ErrorFormat(errNodeExited, e.node.Addr, status, e.node.Log)
+			case <-time.After(pollInterval):
+			}
+		}
+	}
+	return nil
+}
+
+/* Send every node of cluster still running SIGTERM, and wait until they
+ * stopped. Nodes still running after timeout are killed. Processes that are
+ * not nodes of cluster are never signalled.
+ * Return error if any node was killed. */
+func stopNodes(m *manifest, timeout time.Duration) error {
+
+	for _, n := range m.Nodes {
+		if p, err := os.FindProcess(n.PID); m.running(n) && err == nil {
+			p.Signal(syscall.SIGTERM)
+		}
+	}
+	deadline := time.Now().Add(timeout)
+
+	var first error
+	for _, n := range m.Nodes {
+		for m.running(n) {
+			if time.Now().After(deadline) {
+				if p, err := os.FindProcess(n.PID); err == nil {
+					p.Kill()
+				}
+				if first == nil {
+					first = util.ErrorFormat(errNodeKilled, n.Addr, n.PID, timeout)
+				}
+				break
+			}
+			time.Sleep(pollInterval)
+		}
+	}
+	return first
+}
+
+/* Return true if node runs as a process of cluster; its pid runs paxosd
+ * with config of cluster and address of node. A pid since taken by
+ * another process is not the node. */
+func (m *manifest) running(n *clusterNode) bool {
+	if n.PID <= 0 {
+		return false
+	}
+	argv, err := processArgs(n.PID)
+	return err == nil && hasArgs(argv, "-config", m.Config, "-addr", n.Addr)
+}
+
+/* Return true if argv holds args, in order and next to each other. */
+func hasArgs(argv []string, args ...string) bool {
+	for i := 0; i+len(args) <= len(argv); i++ {
+		if slices.Equal(argv[i:i+len(args)], args) {
+			return true
+		}
+	}
+	return false
+}
+
+/* Write every line logged by nodes, prefixed by node, until interrupted.
+ * Nodes that exit are reported once. */
+func tailLogs(cmdarg *CmdArg, m *manifest) error {
+
+	/* Interrupts end tail successfully. */
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var mu sync.Mutex // guards stdout
+	var wg sync.WaitGroup
+	errs := make(chan error, len(m.Nodes))
+
+	for _, n := range m.Nodes {
+		wg.Add(1)
+		go func(n *clusterNode) {
+			defer wg.Done()
+			if err := tailLog(ctx, cmdarg, m, n, &mu); err != nil {
+				errs <- err
+				stop()
+			}
+		}(n)
+	}
+	wg.Wait()
+	close(errs)
+
+	/* First error, if any. */
+	return <-errs
+}
+
+/* Write lines logged by node, from first line of its log, until ctx is done.
+ * Caller holds mu while writing. */
+func tailLog(ctx context.Context, cmdarg *CmdArg, m *manifest, n *clusterNode, mu *sync.Mutex) error {
+
+	f, err := os.Open(n.Log)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	write := func(line string) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		entry := struct {
+			Role string `json:"role"` // role of node
+			Addr string `json:"addr"` // address of node
+			Line string `json:"line"` // line logged, without newline
+		}{n.Role, n.Addr, line}
+		return output(cmdarg, &entry, func(w io.Writer) {
+			fmt.Fprintf(w, "%-8s %s | %s\n", n.Role, n.Addr, line)
+		})
+	}
+
+	r, line, up := bufio.NewReader(f), "", true
+	for {
+		/* Lines are written whole; a partial line waits for its newline. */
+		s, err := r.ReadString('\n')
+		line += s
+		if err == nil {
+			if err := write(line[:len(line)-1]); err != nil {
+				return err
+			}
+			line = ""
+			continue
+		} else if err != io.EOF {
+			return err
+		}
+
+		if up && !m.running(n) {
+			up = false
+			if err := write("exited"); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+/* Return path of paxosd; path if given, otherwise paxosd next to executable
+ * of paxos, or in PATH. */
+func lookPaxosd(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	if exe, err := os.Executable(); err == nil {
+		p := filepath.Join(filepath.Dir(exe), "paxosd")
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	p, err := exec.LookPath("paxosd")
+	if err != nil {
+		return "", util.ErrorFormat(errPaxosd, err)
+	}
+	return p, nil
+}
+
+/* Return address of a free port on host.
+ * Port is free once returned, until a node binds it. */
+func freeAddr(host string) (string, error) {
+	l, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	return l.Addr().String(), nil
+}
+
+/* Return manifest of cluster up in dir. */
+func readManifest(dir string) (*manifest, error) {
+	b, err := os.ReadFile(filepath.Join(dir, clusterManifest))
+	if err != nil {
+		return nil, util.ErrorFormat(errClusterDown, dir, err)
+	}
+	m := &manifest{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, util.ErrorFormat(errClusterDown, dir, err)
+	}
+	return m, nil
+}
+
+/* Write v as indented json to file at path. */
+func writeJson(path string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0644)
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package main
+
+import (
+	"errors"
+	"os/exec"
+)
+
+var (
+	/* Errors. */
+	errProcessArgs = errors.New("command line of processes is unix only")
+)
+
+/* Start process of cmd as is; process groups are unix only. */
+func detachProcess(cmd *exec.Cmd) {}
+
+/* Return error; processes are not told apart, so nodes are never signalled. */
+func processArgs(pid int) ([]string, error) {
+	return nil, errProcessArgs
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterDownStale(t *testing.T) {
+
+	/* Manifest of a cluster whose nodes all exited; pids are now those of
+	 * test itself, of no process, and never started. */
+	dir := t.TempDir()
+	m := &manifest{
+		Config: filepath.Join(dir, clusterConfig),
+		Nodes: []*clusterNode{
+			{Role: "proposer", Addr: "localhost:9000", PID: os.Getpid()},
+			{Role: "accepter", Addr: "localhost:9001", PID: math.MaxInt32},
+			{Role: "learner", Addr: "localhost:9002", PID: 0},
+		},
+	}
+	if err := writeJson(filepath.Join(dir, clusterManifest), m); err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range m.Nodes {
+		assert.False(t, m.running(n), "pid [%d] taken as node [%s]", n.PID, n.Addr)
+	}
+	/* Nodes are told by their command line, not their pid. */
+	argv := []string{"paxosd", "-config", m.Config, "-addr", "localhost:9000"}
+	assert.True(t, hasArgs(argv, "-config", m.Config, "-addr", "localhost:9000"))
+	assert.False(t, hasArgs(argv, "-config", m.Config, "-addr", "localhost:9001"))
+
+	defer func(w io.Writer) { stdout = w }(stdout)
+	out := &bytes.Buffer{}
+	stdout = out
+
+	/* Test is still running, so no process was signalled; manifest is removed. */
+	assert.NoError(t, clusterDown([]string{"-dir", dir, "-timeout", "1s"}))
+	assert.NoFileExists(t, filepath.Join(dir, clusterManifest))
+	assert.Equal(t, 3, bytes.Count(out.Bytes(), []byte("not running")), out.String())
+
+	/* Nothing is left to stop. */
+	_, err := readManifest(dir)
+	assert.Error(t, err)
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"strings"
+	"time"
+
+	paxos "github.com/marius-j-i/paxos/node"
+	"github.com/marius-j-i/paxos/util"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	/* Errors. */
+	errConfig      = errors.New("invalid config file [%s]: %s")
+	errNoAddr      = errors.New("no listen address given; set -addr or `addr` in [%s]")
+	errRole        = errors.New("invalid role [%s] for [%s]; expected proposer, accepter or learner")
+	errNoRole      = errors.New("no role for [%s]; set -role, or list it among members")
+	errNoAccepter  = errors.New("no accepter among [%d] members; no quorum can be formed")
+	errPeer        = errors.New("invalid peer [%s]; expected <host:port>=<role>")
+	errTransport   = errors.New("invalid transport [%s]; expected http or grpc")
+	errStorage     = errors.New("invalid storage [%s]; expected file, bolt or memory")
+	errTLS         = errors.New("TLS needs cacert, cert and key together; got [%s], [%s], [%s]")
+	errProposalMax = errors.New("proposal timeout interval [%s, %s] is empty")
+
+	/* Roles by name, as in config files and flags. */
+	roles = map[string]paxos.Role{
+		"proposer": paxos.Proposer,
+		"accepter": paxos.Accepter,
+		"learner":  paxos.Learner,
+	}
+)
+
+/* Configuration of a node, read from a cluster config file, and flags.
+ * Every node of a cluster may share one file, listing every member; a node
+ * finds its own role there by its address. JSON files are read as YAML.
+ */
+type Config struct {
+	Addr      string            `yaml:"addr"`      // address node listens on, and is known by to peers
+	Role      string            `yaml:"role"`      // role of node; or its role among members
+	Members   map[string]string `yaml:"members"`   // address mapping to role of every member, node included
+	Transport string            `yaml:"transport"` // carries peer messages; http, or grpc
+	Storage   string            `yaml:"storage"`   // keeps state; file, bolt, or memory
+	Dir       string            `yaml:"dir"`       // directory of state files
+	Snapshot  int               `yaml:"snapshot"`  // slots applied between snapshots; zero disables snapshots
+	TLS       struct {
+		CACert string `yaml:"cacert"` // path to PEM-file of cluster CA
+		Cert   string `yaml:"cert"`   // path to PEM-file of node certificate
+		Key    string `yaml:"key"`    // path to PEM-file of key of node certificate
+	} `yaml:"tls"`
+	Tracing struct {
+		OTLP string `yaml:"otlp"` // url of OTLP/HTTP endpoint spans are exported to
+		File string `yaml:"file"` // path to file spans are appended to as JSON
+	} `yaml:"tracing"`
+	Timeouts struct {
+		Shutdown    time.Duration `yaml:"shutdown"`     // time to finish requests on shutdown
+		Lease       time.Duration `yaml:"lease"`        // time accepters grant leader a lease for
+		ProposalMin time.Duration `yaml:"proposal_min"` // least time to wait before re-trying a proposal
+		ProposalMax time.Duration `yaml:"proposal_max"` // most time to wait before re-trying a proposal
+	} `yaml:"timeouts"`
+}
+
+/* Return configuration with defaults of nodes.
+ */
+func defaultConfig() *Config {
+	c := &Config{
+		Members:   map[string]string{},
+		Transport: "http",
+		Storage:   "file",
+		Dir:       "nodes",
+	}
+	c.Timeouts.Shutdown = paxos.SHUTDOWNTIMEOUT
+	c.Timeouts.Lease = 2 * time.Second
+	c.Timeouts.ProposalMin = 200 * time.Millisecond
+	c.Timeouts.ProposalMax = 500 * time.Millisecond
+	return c
+}
+
+/* Return configuration from defaults, then config file given by -config,
+ * if any, then any other flag set in arguments.
+ */
+func parseCmdline(arguments []string) (*Config, string, error) {
+	file, set := "", &Config{Members: map[string]string{}}
+
+	args := flag.NewFlagSet("paxosd", flag.ExitOnError)
+
+	args.StringVar(&file, "config", "",
+		"Optional: Path to YAML or JSON cluster config file; flags override its values")
+	args.StringVar(&set.Addr, "addr", "",
+		"Required: Address <host:port> node listens on, and is known by to peers")
+	args.StringVar(&set.Role, "role", "",
+		"Required unless among members of config: Role of node; proposer, accepter or learner")
+	args.Func("peer",
+		"Optional, repeatable: Peer as <host:port>=<role>", func(s string) error {
+			addr, role, ok := strings.Cut(s, "=")
+			if !ok || addr == "" || role == "" {
+				return util.ErrorFormat(errPeer, s)
+			}
+			set.Members[addr] = role
+			return nil
+		})
+	args.StringVar(&set.Transport, "transport", "",
+		"Optional: Transport of peer messages; http (default), or grpc")
+	args.StringVar(&set.Storage, "storage", "",
+		"Optional: Storage of state; file (default), bolt, or memory")
+	args.StringVar(&set.Dir, "dir", "",
+		"Optional: Directory of state files (default nodes)")
+	args.IntVar(&set.Snapshot, "snapshot", 0,
+		"Optional: Slots applied between snapshots; zero (default) disables snapshots")
+	args.StringVar(&set.TLS.CACert, "cacert", "",
+		"Optional: Path to PEM-file of cluster CA; enables mutual TLS together with -cert and -key")
+	args.StringVar(&set.TLS.Cert, "cert", "",
+		"Optional: Path to PEM-file of node certificate issued by cluster CA")
+	args.StringVar(&set.TLS.Key, "key", "",
+		"Optional: Path to PEM-file of key of node certificate")
+	args.StringVar(&set.Tracing.OTLP, "trace-otlp", "",
+		"Optional: Url of OTLP/HTTP endpoint to export spans to, e.g. http://localhost:4318")
+	args.StringVar(&set.Tracing.File, "trace-file", "",
+		"Optional: Path to file to append spans to as JSON")
+	args.DurationVar(&set.Timeouts.Shutdown, "shutdown-timeout", 0,
+		"Optional: Time to finish requests on shutdown (default 8s)")
+	args.DurationVar(&set.Timeouts.Lease, "lease", 0,
+		"Optional: Time accepters grant leader a lease for (default 2s)")
+	args.DurationVar(&set.Timeouts.ProposalMin, "proposal-timeout-min", 0,
+		"Optional: Least time to wait before re-trying a proposal (default 200ms)")
+	args.DurationVar(&set.Timeouts.ProposalMax, "proposal-timeout-max", 0,
+		"Optional: Most time to wait before re-trying a proposal (default 500ms)")
+
+	args.Parse(arguments)
+
+	c := defaultConfig()
+	if file != "" {
+		if err := c.read(file); err != nil {
+			return nil, "", err
+		}
+	}
+	/* Flags set override config file. */
+	args.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "addr":
+			c.Addr = set.Addr
+		case "role":
+			c.Role = set.Role
+		case "transport":
+			c.Transport = set.Transport
+		case "storage":
+			c.Storage = set.Storage
+		case "dir":
+			c.Dir = set.Dir
+		case "snapshot":
+			c.Snapshot = set.Snapshot
+		case "cacert":
+			c.TLS.CACert = set.TLS.CACert
+		case "cert":
+			c.TLS.Cert = set.TLS.Cert
+		case "key":
+			c.TLS.Key = set.TLS.Key
+		case "trace-otlp":
+			c.Tracing.OTLP = set.Tracing.OTLP
+		case "trace-file":
+			c.Tracing.File = set.Tracing.File
+		case "shutdown-timeout":
+			c.Timeouts.Shutdown = set.Timeouts.Shutdown
+		case "lease":
+			c.Timeouts.Lease = set.Timeouts.Lease
+		case "proposal-timeout-min":
+			c.Timeouts.ProposalMin = set.Timeouts.ProposalMin
+		case "proposal-timeout-max":
+			c.Timeouts.ProposalMax = set.Timeouts.ProposalMax
+		}
+	})
+	for addr, role := range set.Members {
+		c.Members[addr] = role
+	}
+	if err := c.validate(file); err != nil {
+		args.Usage()
+		return nil, "", err
+	}
+	return c, file, nil
+}
+
+/* Read config file at path over configuration.
+ */
+func (c *Config) read(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	/* Fields unknown to nodes are mistakes, not extensions. */
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil {
+		return util.ErrorFormat(errConfig, path, err)
+	}
+	return nil
+}
+
+/* Return error unless configuration describes a node that can be started.
+ * Role of node is taken from members, unless given.
+ */
+func (c *Config) validate(file string) error {
+
+	if c.Addr == "" {
+		return util.ErrorFormat(errNoAddr, file)
+	}
+	if c.Role == "" {
+		c.Role = c.Members[c.Addr]
+	}
+	if c.Role == "" {
+		return util.ErrorFormat(errNoRole, c.Addr)
+	}
+	c.Members[c.Addr] = c.Role
+	accepters := 0
+	for addr, role := range c.Members {
+		if _, ok := roles[role]; !ok {
+			return util.ErrorFormat(errRole, role, addr)
+		} else if roles[role] == paxos.Accepter {
+			accepters++
+		}
+	}
+	/* Quorums are formed among accepters. */
+	if accepters == 0 {
+		return util.ErrorFormat(errNoAccepter, len(c.Members))
+	}
+	if c.Transport != "http" && c.Transport != "grpc" {
+		return util.ErrorFormat(errTransport, c.Transport)
+	}
+	if c.Storage != "file" && c.Storage != "bolt" && c.Storage != "memory" {
+		return util.ErrorFormat(errStorage, c.Storage)
+	}
+	/* TLS files are given together, or not at all. */
+	some := c.TLS.CACert != "" || c.TLS.Cert != "" || c.TLS.Key != ""
+	all := c.TLS.CACert != "" && c.TLS.Cert != "" && c.TLS.Key != ""
+	if some && !all {
+		return util.ErrorFormat(errTLS, c.TLS.CACert, c.TLS.Cert, c.TLS.Key)
+	}
+	if c.Timeouts.ProposalMin >= c.Timeouts.ProposalMax {
+		return util.ErrorFormat(errProposalMax, c.Timeouts.ProposalMin, c.Timeouts.ProposalMax)
+	}
+	return nil
+}
+
+/* Return network members mapped to their roles, node included.
+ */
+func (c *Config) network() map[string]paxos.Role {
+	network := make(map[string]paxos.Role, len(c.Members))
+	for addr, role := range c.Members {
+		network[addr] = roles[role]
+	}
+	return network
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/* Cluster config file shared by every node of tests. */
+var clusterYAML = `
+members:
+  localhost:9000: proposer
+  localhost:9001: accepter
+  localhost:9002: learner
+transport: grpc
+storage: bolt
+snapshot: 100
+timeouts:
+  lease: 3s
+  proposal_min: 100ms
+  proposal_max: 300ms
+`
+
+func TestConfig(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		file  string   // content of config file; none if empty
+		args  []string // flags, besides -config if file is given
+		ok    bool     // true if config is valid
+		check func(t *testing.T, c *Config)
+	}{
+		{"yaml file", clusterYAML, []string{"-addr", "localhost:9001"}, true, func(t *testing.T, c *Config) {
+			assert.Equal(t, "accepter", c.Role)
+			assert.Len(t, c.Members, 3)
+			assert.Equal(t, "grpc", c.Transport)
+			assert.Equal(t, "bolt", c.Storage)
+			assert.Equal(t, "nodes", c.Dir)
+			assert.Equal(t, 100, c.Snapshot)
+			assert.Equal(t, 3*time.Second, c.Timeouts.Lease)
+			assert.Equal(t, 100*time.Millisecond, c.Timeouts.ProposalMin)
+		}},
+		{"json file", `{"addr": "localhost:9000", "members": {"localhost:9000": "proposer", "localhost:9001": "accepter"}}`,
+			[]string{}, true, func(t *testing.T, c *Config) {
+				assert.Equal(t, "proposer", c.Role)
+				assert.Equal(t, "http", c.Transport)
+				assert.Equal(t, 200*time.Millisecond, c.Timeouts.ProposalMin)
+			}},
+		{"flags override file", clusterYAML, []string{"-addr", "localhost:9001", "-transport", "http", "-snapshot", "5",
+			"-lease", "1s", "-peer", "localhost:9003=accepter"}, true, func(t *testing.T, c *Config) {
+			assert.Equal(t, "http", c.Transport)
+			assert.Equal(t, "bolt", c.Storage)
+			assert.Equal(t, 5, c.Snapshot)
+			assert.Equal(t, time.Second, c.Timeouts.Lease)
+			assert.Equal(t, "accepter", c.Members["localhost:9003"])
+			assert.Len(t, c.Members, 4)
+		}},
+		{"role flag overrides members", clusterYAML, []string{"-addr", "localhost:9002", "-role", "accepter"}, true,
+			func(t *testing.T, c *Config) {
+				assert.Equal(t, "accepter", c.Role)
+				assert.Equal(t, "accepter", c.Members["localhost:9002"])
+			}},
+		{"flags without file", "", []string{"-addr", "localhost:9001", "-role", "accepter", "-peer", "localhost:9000=proposer"},
+			true, func(t *testing.T, c *Config) {
+				assert.Len(t, c.Members, 2)
+			}},
+		{"unknown key", clusterYAML + "quorum: 2\n", []string{"-addr", "localhost:9001"}, false, nil},
+		{"missing file", "", []string{"-config", filepath.Join(t.TempDir(), "missing.yaml"), "-addr", "localhost:9001"}, false, nil},
+		{"no address", clusterYAML, []string{}, false, nil},
+		{"no role", clusterYAML, []string{"-addr", "localhost:9009"}, false, nil},
+		{"bad role", clusterYAML, []string{"-addr", "localhost:9001", "-peer", "localhost:9003=leader"}, false, nil},
+		{"bad role flag", clusterYAML, []string{"-addr", "localhost:9001", "-role", "leader"}, false, nil},
+		{"no quorum of accepters", "", []string{"-addr", "localhost:9000", "-role", "proposer", "-peer", "localhost:9002=learner"}, false, nil},
+		{"bad transport", clusterYAML, []string{"-addr", "localhost:9001", "-transport", "udp"}, false, nil},
+		{"partial tls", clusterYAML, []string{"-addr", "localhost:9001", "-cacert", "ca.pem"}, false, nil},
+		{"empty proposal timeout", clusterYAML, []string{"-addr", "localhost:9001", "-proposal-timeout-min", "1s"}, false, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			args := c.args
+			if c.file != "" {
+				path := filepath.Join(t.TempDir(), "cluster.yaml")
+				if err := os.WriteFile(path, []byte(c.file), 0644); err != nil {
+					t.Fatal(err)
+				}
+				args = append([]string{"-config", path}, args...)
+			}
+			config, _, err := parseCmdline(args)
+			assert.Equal(t, c.ok, err == nil, "error: %v", err)
+			if c.ok && err == nil {
+				c.check(t, config)
+			}
+		})
+	}
+}
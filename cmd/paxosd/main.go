@@ -0,0 +1,187 @@
+/* Command paxosd serves a single paxos node, as one process of a cluster.
+ *
+ * Usage:
+ *
+ *	paxosd -config cluster.yaml -addr localhost:9001
+ *	paxosd -addr localhost:9001 -role accepter -peer localhost:9000=proposer ...
+ *
+ * The node stops gracefully on SIGTERM or SIGINT, keeping its state for the
+ * next start. Spans of proposals are exported to an OTLP endpoint given by
+ * -trace-otlp, or appended to a file given by -trace-file.
+ */
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	paxos "github.com/marius-j-i/paxos/node"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+
+	c, file, err := parseCmdline(os.Args[1:])
+	if err != nil {
+		exit(err)
+	}
+	flush, err := newTracing(c)
+	if err != nil {
+		exit(err)
+	}
+	n, err := newNode(c)
+	if err != nil {
+		exit(err)
+	}
+	log.Infof("serving %s [%s] of %d members, config [%s]", c.Role, c.Addr, len(c.Members), file)
+
+	/* Serve until signalled, or serving fails. */
+	errchan := make(chan error, 4)
+	go n.Serve(errchan)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+
+	failed, served := false, false
+	select {
+	case sig := <-signals:
+		log.Infof("received %s; shutting down [%s]", sig, c.Addr)
+	case err := <-errchan:
+		/* Serving ended before shutdown; with an error unless nil. */
+		if err != nil {
+			log.Error(err)
+			failed = true
+		} else {
+			served = true
+		}
+	}
+	go n.Shutdown(errchan)
+
+	/* Wait for nil from both serve, unless already received, and shutdown. */
+	for pending := 2; pending > 0; {
+		if served {
+			pending, served = pending-1, false
+			continue
+		}
+		if err := <-errchan; err != nil {
+			log.Error(err)
+			failed = true
+			continue
+		}
+		pending--
+	}
+	flush()
+	if failed {
+		os.Exit(1)
+	}
+}
+
+/* Return node configured by c, restoring any state it saved before.
+ */
+func newNode(c *Config) (*paxos.Node, error) {
+
+	/* State is kept across restarts of the process. */
+	paxos.SetRestorePersistentState(true)
+	paxos.SetPersistAfterShutdown(true)
+
+	paxos.SHUTDOWNTIMEOUT = c.Timeouts.Shutdown
+	paxos.SetLeaseDuration(c.Timeouts.Lease)
+	paxos.SetProposalTimeout(c.Timeouts.ProposalMin, c.Timeouts.ProposalMax)
+	paxos.SetSnapshotInterval(c.Snapshot)
+
+	t, err := newTransport(c)
+	if err != nil {
+		return nil, err
+	}
+	s, err := newStorage(c)
+	if err != nil {
+		return nil, err
+	}
+	return paxos.NewNodeWithStorage(roles[c.Role], c.Addr, c.network(), t, s)
+}
+
+/* Export spans of node as configured by c, if at all.
+ * Return function exporting spans still buffered, at exit.
+ */
+func newTracing(c *Config) (func(), error) {
+
+	if c.Tracing.OTLP == "" && c.Tracing.File == "" {
+		return func() {}, nil
+	}
+	var f *os.File
+	var w io.Writer
+	if c.Tracing.File != "" {
+		var err error
+		if f, err = os.OpenFile(c.Tracing.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+			return nil, err
+		}
+		w = f
+	}
+	tp, err := paxos.NewTracerProvider(c.Role+"-"+c.Addr, c.Tracing.OTLP, w)
+	if err != nil {
+		return nil, err
+	}
+	paxos.SetTracerProvider(tp)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), c.Timeouts.Shutdown)
+		defer cancel()
+		if err := tp.Shutdown(ctx); err != nil {
+			log.Error(err)
+		}
+		if f != nil {
+			f.Close()
+		}
+	}, nil
+}
+
+/* Return transport of peer messages configured by c, with mutual TLS if
+ * certificates are given.
+ */
+func newTransport(c *Config) (paxos.Transport, error) {
+
+	if c.TLS.CACert == "" {
+		if c.Transport == "grpc" {
+			return paxos.NewGrpcTransport(), nil
+		}
+		return paxos.NewHttpTransport(), nil
+	}
+	creds, err := paxos.LoadCredentials(c.TLS.CACert, c.TLS.Cert, c.TLS.Key)
+	if err != nil {
+		return nil, err
+	}
+	if c.Transport == "grpc" {
+		return paxos.NewGrpcTransportTLS(creds), nil
+	}
+	return paxos.NewHttpTransportTLS(creds), nil
+}
+
+/* Return storage configured by c, in a file named from role and address of
+ * node within directory of state files.
+ */
+func newStorage(c *Config) (paxos.Storage, error) {
+
+	path := filepath.Join(c.Dir, c.Role+"-"+c.Addr)
+	switch c.Storage {
+	case "bolt":
+		return paxos.NewBoltStorage(path + ".db")
+	case "memory":
+		return paxos.NewMemoryStorage(), nil
+	default:
+		return paxos.NewFileStorage(path)
+	}
+}
+
+/* Exit program with error code 1 if err is non-nil, otherwise code is 0. */
+func exit(err error) {
+	code := 0
+	if err != nil {
+		log.Error(err)
+		code = 1
+	}
+	os.Exit(code)
+}
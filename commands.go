@@ -0,0 +1,402 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/marius-j-i/paxos/client"
+	paxos "github.com/marius-j-i/paxos/node"
+	"github.com/marius-j-i/paxos/util"
+)
+
+var (
+	/* Errors. */
+	errNoValue = errors.New("node [%s] has no value for slot [%d]")
+
+	/* Subcommands by name. */
+	commands = map[string]*command{
+		"propose":   {run: propose, help: "Propose a value read from an argument, a file or stdin"},
+		"get":       {run: get, help: "Get value accepted, or chosen, for slots"},
+		"accepters": {run: accepters, help: "List accepters alive"},
+		"learners":  {run: learners, help: "List learners alive"},
+		"status":    {run: status, help: "Summarize what node promised, accepted and persisted, and its peers"},
+		"watch":     {run: watch, help: "Follow values accepted, or chosen, as slots are filled"},
+		"cluster":   {run: cluster, help: "Start a local cluster of node processes with up, and stop it with down"},
+	}
+)
+
+/* Subcommand, run with its arguments after its name. */
+type command struct {
+	run  func(arguments []string) error
+	help string
+}
+
+/* Value of a slot, as written by subcommands. */
+type entry struct {
+	Slot   int          `json:"slot"`   // slot index
+	Ballot paxos.Ballot `json:"ballot"` // ballot value was accepted, or chosen, with
+	Value  []byte       `json:"value"`  // value of slot
+}
+
+/* Write entry as text on a line of its own. */
+func (e *entry) write(w io.Writer) {
+	fmt.Fprintf(w, "%d\t%s\t%q\n", e.Slot, e.Ballot, e.Value)
+}
+
+/* Error of request failing with exit code other than that of its error. */
+type codeError struct {
+	code int
+	err  error
+}
+
+func (e *codeError) Error() string {
+	return e.err.Error()
+}
+
+/* Return names of subcommands, sorted. */
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/* Print usage of program, a line for every subcommand with its help. */
+func commandUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: paxos <command> [flags] [operands]\ncommands:")
+	for _, name := range commandNames() {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].help)
+	}
+	fmt.Fprintln(w, "Supply -h after a command for its flags.")
+}
+
+/* paxos propose [<value>]
+ * Value is an argument, a file given by -value, or otherwise read from stdin. */
+func propose(arguments []string) error {
+	cmdarg, path := &CmdArg{}, ""
+
+	args := newFlagSet("propose", "[<value>]", cmdarg)
+	args.StringVar(&path, "value", "",
+		"Optional: Path to file where value for proposer is, or - for stdin (default stdin, unless <value> is given)")
+	operands := parseCmdline(args, cmdarg, arguments)
+
+	var err error
+	switch {
+	case len(operands) > 1, len(operands) == 1 && path != "":
+		usage(args)
+	case len(operands) == 1:
+		cmdarg.value = []byte(operands[0])
+	case path == "", path == "-":
+		cmdarg.value, err = io.ReadAll(os.Stdin)
+	default:
+		cmdarg.value, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	p, err := client.ProposeValue(cmdarg.host, cmdarg.port, cmdarg.value)
+	if err != nil {
+		return err
+	}
+	return output(cmdarg, p, func(w io.Writer) {
+		(&entry{Slot: p.Slot, Ballot: p.Ballot, Value: p.Value}).write(w)
+		if p.Adopted {
+			fmt.Fprintf(w, "slots %v filled with values adopted from earlier proposals first\n", p.Filled)
+		}
+	})
+}
+
+/* paxos get [-chosen] [<slot> [<to>]]
+ * Without slots, value of most recent slot is written. */
+func get(arguments []string) error {
+	cmdarg, chosen := &CmdArg{}, false
+
+	args := newFlagSet("get", "[<slot> [<to>]]", cmdarg)
+	args.BoolVar(&chosen, "chosen", false,
+		"Optional: Get values chosen, as learned by a learner, instead of values accepted")
+	operands := parseCmdline(args, cmdarg, arguments)
+
+	if len(operands) > 2 {
+		usage(args)
+	}
+	slots := make([]int, len(operands))
+	for i := range operands {
+		slot, err := strconv.Atoi(operands[i])
+		if err != nil {
+			usage(args)
+		}
+		slots[i] = slot
+	}
+
+	/* Most recent slot. */
+	if len(slots) == 0 {
+		slot, err := latestSlot(cmdarg, chosen)
+		if err != nil {
+			return err
+		}
+		slots = append(slots, slot)
+	}
+	from, to := slots[0], slots[len(slots)-1]
+	if from > to {
+		usage(args)
+	}
+
+	for i := from; i <= to; i++ {
+		e, err := getEntry(cmdarg, chosen, i)
+		if err != nil {
+			return err
+		}
+		if err := output(cmdarg, e, e.write); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/* paxos accepters */
+func accepters(arguments []string) error {
+	cmdarg := &CmdArg{}
+	parseCmdline(newFlagSet("accepters", "", cmdarg), cmdarg, arguments)
+
+	a, err := client.GetAccepters(cmdarg.host, cmdarg.port)
+	if err != nil {
+		return err
+	}
+	body := map[string][]string{paxos.JsonKeyAccepters: a}
+	return output(cmdarg, body, func(w io.Writer) {
+		for _, addr := range a {
+			fmt.Fprintln(w, addr)
+		}
+	})
+}
+
+/* paxos learners */
+func learners(arguments []string) error {
+	cmdarg := &CmdArg{}
+	parseCmdline(newFlagSet("learners", "", cmdarg), cmdarg, arguments)
+
+	l, err := client.GetLearners(cmdarg.host, cmdarg.port)
+	if err != nil {
+		return err
+	}
+	body := map[string][]string{paxos.JsonKeyLearners: l}
+	return output(cmdarg, body, func(w io.Writer) {
+		for _, addr := range l {
+			fmt.Fprintln(w, addr)
+		}
+	})
+}
+
+/* paxos status [-debug]
+ * Node is reachable if status is written. */
+func status(arguments []string) error {
+	cmdarg, debug := &CmdArg{}, false
+
+	args := newFlagSet("status", "", cmdarg)
+	args.BoolVar(&debug, "debug", false,
+		"Optional: Also write internals of node; its lease, snapshot and proposals in progress")
+	parseCmdline(args, cmdarg, arguments)
+
+	s := struct {
+		Node      string       `json:"node"`      // address of node
+		Slot      int          `json:"slot"`      // most recent slot with an accepted value
+		Ballot    paxos.Ballot `json:"ballot"`    // ballot value of slot was accepted with
+		Accepters []string     `json:"accepters"` // accepters alive
+		Learners  []string     `json:"learners"`  // learners alive
+		Status    interface{}  `json:"status"`    // status of node, and its internals with -debug
+	}{Node: net.JoinHostPort(cmdarg.host, cmdarg.port)}
+
+	var err error
+	if _, s.Slot, s.Ballot, err = client.GetAccepted(cmdarg.host, cmdarg.port); err != nil {
+		return err
+	} else if s.Accepters, err = client.GetAccepters(cmdarg.host, cmdarg.port); err != nil {
+		return err
+	} else if s.Learners, err = client.GetLearners(cmdarg.host, cmdarg.port); err != nil {
+		return err
+	}
+	state := &paxos.State{}
+	if debug {
+		if state, err = client.GetState(cmdarg.host, cmdarg.port); err != nil {
+			return err
+		}
+		s.Status = state
+	} else {
+		st, err := client.GetStatus(cmdarg.host, cmdarg.port)
+		if err != nil {
+			return err
+		}
+		state.Status, s.Status = *st, st
+	}
+	return output(cmdarg, &s, func(w io.Writer) {
+		fmt.Fprintf(w, "node		%s\n", s.Node)
+		fmt.Fprintf(w, "role		%s, id %d\n", state.Role, state.ID)
+		fmt.Fprintf(w, "leader		%s\n", orNone(state.Leader))
+		fmt.Fprintf(w, "quorums		%s %s of %d accepters, fast %d\n", state.System, state.Quorums, state.Accepters, state.Fast)
+		fmt.Fprintf(w, "accepted	slot %d, ballot %s\n", s.Slot, s.Ballot)
+		fmt.Fprintf(w, "promised	ballot %s\n", state.Promised)
+		fmt.Fprintf(w, "digest		%s\n", state.Digest)
+		fmt.Fprintf(w, "storage		%s, synced %s\n", orNone(state.Storage.Path), ago(state.Storage.Synced))
+		fmt.Fprintf(w, "in flight	%d proposals\n", state.InFlight)
+		fmt.Fprintf(w, "accepters	%d alive: %s\n", len(s.Accepters), strings.Join(s.Accepters, " "))
+		fmt.Fprintf(w, "learners	%d alive: %s\n", len(s.Learners), strings.Join(s.Learners, " "))
+
+		addrs := make([]string, 0, len(state.Peers))
+		for addr := range state.Peers {
+			addrs = append(addrs, addr)
+		}
+		sort.Strings(addrs)
+		for _, addr := range addrs {
+			p := state.Peers[addr]
+			fmt.Fprintf(w, "peer		%s %s, seen %s, rtt %.2fms", addr, p.Role, ago(p.LastSeen), p.RTT)
+			if p.LastError != "" {
+				fmt.Fprintf(w, ", failed %s: %s", ago(p.FailedAt), p.LastError)
+			}
+			fmt.Fprintln(w)
+		}
+		if !debug {
+			return
+		}
+		fmt.Fprintf(w, "ballot		%s\n", state.Ballot)
+		if l := state.Lease; l != nil {
+			fmt.Fprintf(w, "lease		%s from slot %d, ballot %s, expires %s\n", l.Leader, l.Slot, l.Ballot, l.Expires.Format(time.RFC3339Nano))
+		}
+		fmt.Fprintf(w, "snapshot	slot %d, applied %d\n", state.Snapshot, state.Applied)
+		fmt.Fprintf(w, "reserved	%v\n", state.Reserved)
+		for _, p := range state.Proposals {
+			fmt.Fprintf(w, "proposal	slot %d, attempt %d, %d bytes, started %s", p.Slot, p.Attempt, p.Size, ago(p.Started))
+			if p.Forwarded {
+				fmt.Fprint(w, ", forwarded")
+			}
+			fmt.Fprintln(w)
+		}
+	})
+}
+
+/* Return s, or "none" if empty. */
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+/* Return time since t, or "never" if zero. */
+func ago(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return time.Since(t).Round(time.Millisecond).String() + " ago"
+}
+
+/* paxos watch [-chosen] [-from <slot>] [-count <n>]
+ * Values are written in slot order as slots are filled, until count values
+ * are written, or until interrupted. */
+func watch(arguments []string) error {
+	cmdarg, chosen, from, count, interval := &CmdArg{}, false, 0, 0, time.Duration(0)
+
+	args := newFlagSet("watch", "", cmdarg)
+	args.BoolVar(&chosen, "chosen", false,
+		"Optional: Watch values chosen, as learned by a learner, instead of values accepted")
+	args.IntVar(&from, "from", 0,
+		"Optional: First slot to write (default slot after most recent)")
+	args.IntVar(&count, "count", 0,
+		"Optional: Exit after writing count values; zero (default) watches until interrupted")
+	args.DurationVar(&interval, "interval", 500*time.Millisecond,
+		"Optional: Time between polling node for new values")
+	parseCmdline(args, cmdarg, arguments)
+
+	if interval <= 0 || count < 0 {
+		usage(args)
+	}
+
+	/* Interrupts end watch successfully. */
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	next := from
+	if next <= 0 {
+		last, err := latestSlot(cmdarg, chosen)
+		if err != nil && exitCode(err) != exitNotFound {
+			return err
+		}
+		next = last + 1
+	}
+	poll := time.NewTicker(interval)
+	defer poll.Stop()
+
+	for written := 0; count == 0 || written < count; {
+		last, err := latestSlot(cmdarg, chosen)
+		if err != nil && exitCode(err) != exitNotFound {
+			return err
+		}
+		/* Slots are written in order; a slot not yet filled is waited for. */
+		for ; next <= last && (count == 0 || written < count); next++ {
+			e, err := getEntry(cmdarg, chosen, next)
+			if exitCode(err) == exitNotFound {
+				break
+			} else if err != nil {
+				return err
+			}
+			if err := output(cmdarg, e, e.write); err != nil {
+				return err
+			}
+			written++
+		}
+		if count != 0 && written >= count {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-poll.C:
+		}
+	}
+	return nil
+}
+
+/* Return most recent slot with a value accepted by node, or chosen if chosen.
+ */
+func latestSlot(cmdarg *CmdArg, chosen bool) (int, error) {
+	if chosen {
+		_, slot, _, err := client.GetChosenLatest(cmdarg.host, cmdarg.port)
+		return slot, err
+	}
+	_, slot, _, err := client.GetAccepted(cmdarg.host, cmdarg.port)
+	return slot, err
+}
+
+/* Return value of slot accepted by node, or chosen if chosen.
+ * Return error exiting as not found if node has no value for slot.
+ */
+func getEntry(cmdarg *CmdArg, chosen bool, slot int) (*entry, error) {
+	e := &entry{Slot: slot}
+
+	var err error
+	if chosen {
+		e.Value, e.Ballot, err = client.GetChosen(cmdarg.host, cmdarg.port, slot)
+	} else {
+		e.Value, e.Ballot, err = client.GetAcceptedSlot(cmdarg.host, cmdarg.port, slot)
+	}
+	if err != nil {
+		return nil, err
+	}
+	/* Accepters respond with an empty slot for slots they accepted nothing for. */
+	if e.Ballot.IsZero() {
+		err := util.ErrorFormat(errNoValue, net.JoinHostPort(cmdarg.host, cmdarg.port), slot)
+		return nil, &codeError{code: exitNotFound, err: err}
+	}
+	return e, nil
+}
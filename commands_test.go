@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marius-j-i/paxos/client"
+	paxos "github.com/marius-j-i/paxos/node"
+	"github.com/stretchr/testify/assert"
+)
+
+/* Return host and port of server. */
+func hostPort(t *testing.T, s *httptest.Server) (string, string) {
+	host, port, err := net.SplitHostPort(s.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return host, port
+}
+
+func TestExitCode(t *testing.T) {
+
+	cases := []struct {
+		name   string
+		status int // status code node responds to proposal with; zero if node is down
+		err    error
+		code   int
+	}{
+		{"created", http.StatusCreated, nil, exitOK},
+		{"internal error", http.StatusInternalServerError, nil, exitError},
+		{"other error", 0, errors.New("no value"), exitError},
+		{"usage", 0, &codeError{code: exitUsage, err: errors.New("no slot")}, exitUsage},
+		{"unreachable", 0, nil, exitUnreachable},
+		{"not found", http.StatusNotFound, nil, exitNotFound},
+		{"bad request", http.StatusBadRequest, nil, exitRejected},
+		{"conflict", http.StatusConflict, nil, exitRejected},
+		{"too large", http.StatusRequestEntityTooLarge, nil, exitRejected},
+		{"unavailable", http.StatusServiceUnavailable, nil, exitUnavailable},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(c.status)
+				json.NewEncoder(w).Encode(&paxos.Proposal{Slot: 1})
+			}))
+			host, port := hostPort(t, s)
+
+			/* Errors other than those of requests are given as is. */
+			err := c.err
+			if c.err == nil {
+				if c.status == 0 {
+					s.Close()
+				}
+				_, err = client.ProposeValue(host, port, []byte("value"))
+			}
+			s.Close()
+			assert.Equal(t, c.code, exitCode(err), "error: %v", err)
+		})
+	}
+}
+
+func TestProposeInput(t *testing.T) {
+
+	/* Value proposed is recorded, and chosen as is. */
+	var proposed []byte
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		proposed, _ = io.ReadAll(req.Body)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(&paxos.Proposal{Slot: 1, Value: proposed})
+	}))
+	defer s.Close()
+	host, port := hostPort(t, s)
+
+	file := filepath.Join(t.TempDir(), "value")
+	if err := os.WriteFile(file, []byte("from file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	/* Output is not of interest. */
+	defer func(w io.Writer) { stdout = w }(stdout)
+	stdout = io.Discard
+
+	cases := []struct {
+		name  string
+		args  []string
+		value string
+	}{
+		{"argument over stdin", []string{"from argument"}, "from argument"},
+		{"file over stdin", []string{"-value", file}, "from file"},
+		{"stdin by dash", []string{"-value", "-"}, "from stdin"},
+		{"stdin by default", []string{}, "from stdin"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			/* Stdin always has a value, so it is only proposed if chosen. */
+			r, w, err := os.Pipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.WriteString("from stdin")
+			w.Close()
+			defer func(f *os.File) { os.Stdin = f }(os.Stdin)
+			os.Stdin = r
+
+			proposed = nil
+			args := append([]string{"-host", host, "-port", port}, c.args...)
+			assert.NoError(t, propose(args))
+			assert.Equal(t, c.value, string(proposed))
+		})
+	}
+}
+
+func TestProposeAdopted(t *testing.T) {
+
+	/* Proposer filled a slot with an adopted value before value proposed. */
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		value, _ := io.ReadAll(req.Body)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(&paxos.Proposal{Slot: 2, Value: value, Adopted: true, Filled: []int{1}})
+	}))
+	defer s.Close()
+	host, port := hostPort(t, s)
+
+	defer func(w io.Writer) { stdout = w }(stdout)
+	out := &bytes.Buffer{}
+	stdout = out
+
+	assert.NoError(t, propose([]string{"-host", host, "-port", port, "value"}))
+	assert.Contains(t, out.String(), "slots [1] filled with values adopted")
+}
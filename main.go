@@ -1,76 +1,196 @@
+/* Command paxos is a client of a paxos cluster, with a subcommand for every
+ * request to a node.
+ *
+ * Usage:
+ *
+ *	paxos propose -host localhost -port 9000 [-value <file> | <value>]
+ *	paxos get -host localhost -port 9000 [-chosen] [<slot> [<to>]]
+ *	paxos accepters|learners|status -host localhost -port 9000
+ *	paxos watch -host localhost -port 9000 [-chosen] [-from <slot>] [-count <n>]
+ *	paxos cluster up|down|logs [-dir <dir>]
+ *
+ * Every subcommand writes json instead of text with -json, and exits with a
+ * code telling why a request failed.
+ */
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"os"
+	"strings"
 
 	"github.com/marius-j-i/paxos/client"
+	"github.com/marius-j-i/paxos/util"
 	log "github.com/sirupsen/logrus"
 )
 
+var (
+	/* Errors. */
+	errCommand = errors.New("unknown command [%s]; expected one of %s")
+
+	/* Exit codes. */
+	exitOK          = 0 // request succeeded
+	exitError       = 1 // request failed for another reason than below
+	exitUsage       = 2 // command-line is invalid
+	exitUnreachable = 3 // node could not be reached
+	exitNotFound    = 4 // node has no value for slot requested
+	exitRejected    = 5 // node refused request, or proposal was rejected
+	exitUnavailable = 6 // node could not reach a quorum of accepters
+
+	/* Output. */
+	stdout io.Writer = os.Stdout
+)
+
 /* Structure representing arguments from command-line. */
 type CmdArg struct {
-	host, port string
-	value      string
+	host, port        string
+	value             []byte
+	cacert, cert, key string
+	json              bool
 }
 
 func main() {
 
-	cmdarg, err := parseCmdline()
-	if err != nil {
-		exit(err)
+	/* Without a subcommand, arguments are those of propose, as before subcommands. */
+	name, args := "propose", os.Args[1:]
+	switch {
+	case len(args) == 0:
+		commandUsage(os.Stderr)
+		os.Exit(exitUsage)
+	case args[0] == "help", args[0] == "-h", args[0] == "-help", args[0] == "--help":
+		commandUsage(os.Stdout)
+		os.Exit(exitOK)
+	case !strings.HasPrefix(args[0], "-"):
+		name, args = args[0], args[1:]
 	}
-
-	err = client.Propose(cmdarg.host, cmdarg.port, cmdarg.value)
-	if err != nil {
-		exit(err)
+	cmd, ok := commands[name]
+	if !ok {
+		log.Error(util.ErrorFormat(errCommand, name, strings.Join(commandNames(), ", ")))
+		os.Exit(exitUsage)
 	}
+	exit(cmd.run(args))
 }
 
-/* Return struct representing arguments from command-line. */
-func parseCmdline() (*CmdArg, error) {
-	cmdarg := &CmdArg{}
-
-	/* Closure creating value struct into cmdarg. */
-	value := func(path string) error {
-		v, err := os.ReadFile(path)
-		if err != nil {
-			return err
-		}
-		cmdarg.value = string(v)
-		return nil
-	}
+/* Return flag-set of subcommand name, with flags common to every subcommand
+ * set into cmdarg. Operands of subcommand are described by operands. */
+func newFlagSet(name, operands string, cmdarg *CmdArg) *flag.FlagSet {
 
-	args := flag.NewFlagSet("args", flag.ExitOnError)
+	args := newUsageFlagSet(name, operands)
 
 	args.StringVar(&cmdarg.host, "host", "",
-		"Required: Resolvable hostname for machine to reach a proposer")
+		"Required: Resolvable hostname for machine to reach a node")
 	args.StringVar(&cmdarg.port, "port", "",
-		"Required: Port for proposer process on -host")
-	args.Func("value",
-		"Required: Path to file where value for proposer is", value)
+		"Required: Port for node process on -host")
+	args.StringVar(&cmdarg.cacert, "cacert", "",
+		"Optional: Path to PEM-file of cluster CA; enables TLS together with -cert and -key")
+	args.StringVar(&cmdarg.cert, "cert", "",
+		"Optional: Path to PEM-file of client certificate issued by cluster CA")
+	args.StringVar(&cmdarg.key, "key", "",
+		"Optional: Path to PEM-file of key of client certificate")
+	args.BoolVar(&cmdarg.json, "json", false,
+		"Optional: Write output as json instead of text")
+
+	return args
+}
+
+/* Return flag-set of subcommand name, printing operands in its usage. */
+func newUsageFlagSet(name, operands string) *flag.FlagSet {
+
+	args := flag.NewFlagSet(name, flag.ExitOnError)
+	args.Usage = func() {
+		fmt.Fprintf(args.Output(), "usage: paxos %s [flags] %s\n", name, operands)
+		args.PrintDefaults()
+	}
+	return args
+}
+
+/* Parse arguments of subcommand into args, and exit on usage error.
+ * Client is set to use TLS if given. Return operands after flags. */
+func parseCmdline(args *flag.FlagSet, cmdarg *CmdArg, arguments []string) []string {
 
-	args.Parse(os.Args[1:])
+	args.Parse(arguments)
 
 	/* Required arguments. */
-	if cmdarg.host == "" || cmdarg.port == "" || cmdarg.value == "" {
-		args.Usage()
-		exit(nil)
+	if cmdarg.host == "" || cmdarg.port == "" {
+		usage(args)
+	}
+	/* TLS arguments are given together, or not at all. */
+	some := cmdarg.cacert != "" || cmdarg.cert != "" || cmdarg.key != ""
+	all := cmdarg.cacert != "" && cmdarg.cert != "" && cmdarg.key != ""
+	if some && !all {
+		usage(args)
 	}
 
-	return cmdarg, nil
+	/* Nodes with mutual TLS require a client certificate. */
+	if all {
+		if err := client.SetTLS(cmdarg.cacert, cmdarg.cert, cmdarg.key); err != nil {
+			exit(err)
+		}
+	}
+	return args.Args()
 }
 
-/* Exit program with error code 1 if err is non-nil, otherwise code is 0. */
+/* Print usage of subcommand and exit with usage code. */
+func usage(args *flag.FlagSet) {
+	args.Usage()
+	os.Exit(exitUsage)
+}
+
+/* Write v as json if asked to, otherwise write text of v by text. */
+func output(cmdarg *CmdArg, v interface{}, text func(w io.Writer)) error {
+	if cmdarg.json {
+		return json.NewEncoder(stdout).Encode(v)
+	}
+	text(stdout)
+	return nil
+}
+
+/* Exit program with code telling why err failed request, or code 0 if nil. */
 func exit(err error) {
-	code := 0
+	code := exitCode(err)
 	if err != nil {
 		log.Error(err)
-		code = 1
 	}
 	os.Exit(code)
 }
 
+/* Return exit code of error; status codes of nodes are mapped to codes of
+ * their reason, and errors of connections to unreachable. */
+func exitCode(err error) int {
+	var coded *codeError
+	var status *client.StatusError
+	var neterr net.Error
+
+	switch {
+	case err == nil:
+		return exitOK
+
+	case errors.As(err, &coded):
+		return coded.code
+
+	case errors.As(err, &status):
+		switch status.Code {
+		case http.StatusNotFound:
+			return exitNotFound
+		case http.StatusServiceUnavailable:
+			return exitUnavailable
+		case http.StatusBadRequest, http.StatusConflict, http.StatusRequestEntityTooLarge:
+			return exitRejected
+		}
+		return exitError
+
+	case errors.As(err, &neterr):
+		return exitUnreachable
+	}
+	return exitError
+}
+
 /* Struct for proposing value. */
 type Value struct {
 	/* Path to file to read value from. */
@@ -1,11 +1,13 @@
 package paxos
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
 	"github.com/marius-j-i/paxos/util"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 /* /prepare
@@ -13,26 +15,30 @@ import (
  */
 
 func (n *Node) PostPrepare(w http.ResponseWriter, req *http.Request) {
-	var N int
 	defer req.Body.Close()
 
-	/* Assert Role. */
-	if n.role != Accepter && n.role != Learner {
-		err := util.ErrorFormat(errWrongNodeType, "accepter|learner", req.URL)
+	/* Get slot and proposal ballot from url. */
+	slot, err := n.getVarInt(req, varSlot)
+	if err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	/* Assert Role for slot. */
+	if !n.isAt(Accepter, slot) {
+		err := util.ErrorFormat(errWrongNodeType, "accepter", req.URL)
 		n.respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	/* Get proposal from url. */
-	N, err := n.getVarInt(req, varN)
+	b, err := n.getVarBallot(req, varBallot)
 	if err != nil {
 		n.respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	/* No promise to a higher proposal. */
-	if n.prepare < N {
-		n.prepare = N
-	} /* else; create promise with N' > N. */
-	p := newPromise().setNode(n)
+	p, err := n.prepare(requestContext(req), slot, b)
+	if err != nil {
+		n.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
 	/* Respond with appropriate promise. */
 	if err := json.NewEncoder(w).Encode(&p); err != nil {
 		n.respondError(w, http.StatusInternalServerError, err.Error())
@@ -42,39 +48,47 @@ func (n *Node) PostPrepare(w http.ResponseWriter, req *http.Request) {
 }
 
 /* /accept
- * Role - Accepter or Learner
+ * Role - Accepter
  */
 
 func (n *Node) PostAccept(w http.ResponseWriter, req *http.Request) {
 	defer req.Body.Close()
 
-	/* Assert Role. */
-	if n.role != Accepter && n.role != Learner {
-		msg := util.ErrorFormat(errWrongNodeType, "accepter|learner", req.URL).Error()
+	/* Get slot and proposal from url, kind from header, and value from body. */
+	slot, err := n.getVarInt(req, varSlot)
+	if err != nil {
+		n.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	/* Assert Role for slot. */
+	if !n.isAt(Accepter, slot) {
+		msg := util.ErrorFormat(errWrongNodeType, "accepter", req.URL).Error()
 		n.respondError(w, http.StatusBadRequest, msg)
 		return
 	}
-	/* Get proposal and value from url. */
-	N, err := n.getVarInt(req, varN)
+	b, err := n.getVarBallot(req, varBallot)
 	if err != nil {
 		n.respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	v, err := n.getVarString(req, varValue)
+	k, err := n.getHeaderKind(req)
 	if err != nil {
-		n.respondError(w, http.StatusInternalServerError, err.Error())
+		n.respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	/* Reject accept proposal. */
-	if N < n.prepare {
-		log.Infof("reject proposal N [%d] in favor of prepare proposal N' [%d] ",
-			N, n.prepare)
-
-	} else /* Accept proposal. */ if err := n.commit(N, v); err != nil {
+	v, code, err := n.getBodyValue(w, req)
+	if err != nil {
+		n.respondError(w, code, err.Error())
+		return
+	}
+	p, accepted, err := n.accept(requestContext(req), slot, b, k, v)
+	if err != nil {
 		n.respondError(w, http.StatusInternalServerError, err.Error())
 		return
+	} else if accepted {
+		/* Notify learners of acceptance. */
+		go n.notifyLearners(slot, b, k, v)
 	}
-	p := newPromise().setNode(n)
 	/* Respond with appropriate promise. */
 	if err := json.NewEncoder(w).Encode(&p); err != nil {
 		n.respondError(w, http.StatusInternalServerError, err.Error())
@@ -82,3 +96,96 @@ func (n *Node) PostAccept(w http.ResponseWriter, req *http.Request) {
 	}
 	// w.WriteHeader(http.StatusOK)
 }
+
+/* Promise ballot b for slot, unless promised to a higher ballot, or
+ * another proposer than that of b holds a lease.
+ * Decision and its persistence are atomic.
+ * Return promise describing slot after decision.
+ */
+func (n *Node) prepare(ctx context.Context, slot int, b Ballot) (*Promise, error) {
+	_, span := startSpan(ctx, "paxos.prepare.receive",
+		append(slotAttributes(slot, b), attribute.String("paxos.node", n.server.Addr))...)
+	defer span.End()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.metrics.received.WithLabelValues(phasePrepare).Inc()
+
+	/* Compacted slots were chosen; refuse any new proposal for them. */
+	if slot <= n.snapshotSlotLocked() {
+		n.metrics.rejected.WithLabelValues(phasePrepare).Inc()
+		return newPromise().setNode(n, newSlot(), slot), nil
+	}
+	s := n.slotLocked(slot)
+	/* No promise to a higher proposal, nor to other proposers than leader. */
+	if s.Prepare.Less(b) && !n.leasedToOther(b) {
+		s.Prepare = b
+		if err := n.persistLocked(slot); err != nil {
+			return nil, err
+		}
+	} else /* create promise with b' > b, or lease of leader. */ {
+		n.metrics.rejected.WithLabelValues(phasePrepare).Inc()
+	}
+	return newPromise().setNode(n, s, slot), nil
+}
+
+/* Accept ballot b with value v of kind k for slot, unless promised to a higher ballot.
+ * Decision and its persistence are atomic.
+ * Return promise describing slot after decision, and true if accepted.
+ */
+func (n *Node) accept(ctx context.Context, slot int, b Ballot, k Kind, v []byte) (*Promise, bool, error) {
+	_, span := startSpan(ctx, "paxos.accept.receive",
+		append(slotAttributes(slot, b), attribute.String("paxos.node", n.server.Addr))...)
+	defer span.End()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.metrics.received.WithLabelValues(phaseAccept).Inc()
+
+	/* Compacted slots were chosen; refuse any new proposal for them. */
+	if slot <= n.snapshotSlotLocked() {
+		n.metrics.rejected.WithLabelValues(phaseAccept).Inc()
+		return newPromise().setNode(n, newSlot(), slot), false, nil
+	}
+	/* Reject accept proposal. */
+	s := n.slotLocked(slot)
+	if b.Less(s.Prepare) {
+		n.metrics.rejected.WithLabelValues(phaseAccept).Inc()
+		log.Infof("reject proposal [%s] in favor of prepare proposal [%s] for slot [%d]",
+			b, s.Prepare, slot)
+		return newPromise().setNode(n, s, slot), false, nil
+	}
+	/* Accept proposal, which implies a promise to it. */
+	s.Prepare, s.N, s.Value, s.Kind = b, b, v, k
+	if err := n.persistLocked(slot); err != nil {
+		return nil, false, err
+	}
+	return newPromise().setNode(n, s, slot), true, nil
+}
+
+/* Fan-out acceptance of ballot b with value v of kind k for slot to learners,
+ * and to coordinator if accepted in a fast round.
+ * Learners decide when value is chosen; unreachable learners are only logged.
+ */
+func (n *Node) notifyLearners(slot int, b Ballot, k Kind, v []byte) {
+
+	/* Go routine. */
+	learn := func(addr string) {
+		if err := n.transport.Accepted(addr, n.server.Addr, slot, b, k, v); err != nil {
+			log.Debug(err)
+		}
+	}
+	/* Send acceptance to learners, and coordinator of fast round. */
+	coordinator := ``
+	if b == fastBallot {
+		coordinator = n.coordinator()
+	}
+	for addr, role := range n.config(slot).Network {
+		if role != Learner && addr != coordinator {
+			continue
+		}
+		go learn(addr)
+	}
+}
@@ -0,0 +1,111 @@
+package paxos
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/marius-j-i/paxos/util"
+)
+
+var (
+	/* Errors. */
+	errBallotFormat = errors.New("ballot [%s] not on format `<round>.<id>`")
+
+	/* Separator between round and proposer id in ballot string. */
+	ballotSeparator = "."
+)
+
+/* Globally unique proposal number.
+ * Ordered lexicographically by round, then proposer id.
+ */
+type Ballot struct {
+	Round int `json:"round"` // proposal round, incremented on every new proposal
+	ID    int `json:"id"`    // unique id of proposer that issued ballot
+}
+
+/* Return ballot for proposer id that is greater than argument ballots.
+ */
+func nextBallot(id int, ballots ...Ballot) Ballot {
+	round := 0
+	for _, b := range ballots {
+		if b.Round > round {
+			round = b.Round
+		}
+	}
+	return Ballot{Round: round + 1, ID: id}
+}
+
+/* Return ballot for proposal on slot, unique among all proposals from node.
+ */
+func (n *Node) newBallot(slot int) Ballot {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	s := n.slotLocked(slot)
+	b := nextBallot(n.id, s.N, s.Prepare, n.ballot)
+	s.Prepare, n.ballot = b, b
+	return b
+}
+
+/* Record ballot b as promised for slot, and as seen by node.
+ */
+func (n *Node) seenBallot(slot int, b Ballot) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if s := n.slotLocked(slot); b.Greater(s.Prepare) {
+		s.Prepare = b
+	}
+	if b.Greater(n.ballot) {
+		n.ballot = b
+	}
+}
+
+/* Return ballot parsed from string on format `<round>.<id>`.
+ */
+func parseBallot(s string) (Ballot, error) {
+
+	round, id, ok := strings.Cut(s, ballotSeparator)
+	if !ok {
+		return Ballot{}, util.ErrorFormat(errBallotFormat, s)
+	}
+	r, err := strconv.Atoi(round)
+	if err != nil {
+		return Ballot{}, err
+	}
+	i, err := strconv.Atoi(id)
+	if err != nil {
+		return Ballot{}, err
+	}
+	return Ballot{Round: r, ID: i}, nil
+}
+
+/* Return true if ballot is less than argument ballot.
+ */
+func (b Ballot) Less(other Ballot) bool {
+	if b.Round != other.Round {
+		return b.Round < other.Round
+	}
+	return b.ID < other.ID
+}
+
+/* Return true if ballot is greater than argument ballot.
+ */
+func (b Ballot) Greater(other Ballot) bool {
+	return other.Less(b)
+}
+
+/* Return true if no proposer has issued ballot.
+ */
+func (b Ballot) IsZero() bool {
+	return b == Ballot{}
+}
+
+/* Return ballot on format `<round>.<id>`.
+ * Implements Stringer interface.
+ */
+func (b Ballot) String() string {
+	return fmt.Sprintf("%d%s%d", b.Round, ballotSeparator, b.ID)
+}
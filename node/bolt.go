@@ -0,0 +1,174 @@
+package paxos
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	/* Buckets. */
+	bucketLog      = []byte("log")      // slot index mapping to slot
+	bucketLease    = []byte("lease")    // keyLease mapping to lease granted, if any
+	bucketSnapshot = []byte("snapshot") // keySnapshot mapping to latest snapshot, if any
+	keyLease       = []byte("lease")
+	keySnapshot    = []byte("snapshot")
+
+	/* Timeouts. */
+	boltOpenTimeout = time.Second // time to wait for another process to release database file
+)
+
+/* Storage keeping state in an embedded key-value database, where every slot
+ * is a key, and every value a record. Saves only write the slots given, in
+ * one synced transaction.
+ */
+type BoltStorage struct {
+	db *bolt.DB // database file state is kept in
+}
+
+/* Return storage keeping state in database file at path. Directories are created.
+ */
+func NewBoltStorage(path string) (*BoltStorage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		return nil, err
+	}
+	/* Buckets exist for every later transaction. */
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{bucketLog, bucketLease, bucketSnapshot} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltStorage{db: db}, nil
+}
+
+/* Return key of slot index, ordered as slots are.
+ */
+func slotKey(i int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(i))
+	return key
+}
+
+func (s *BoltStorage) Save(slots map[int]Slot, lease *Lease) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+
+		log := tx.Bucket(bucketLog)
+		for i := range slots {
+			slot := slots[i]
+			b, err := encodeRecord(&slot)
+			if err != nil {
+				return err
+			} else if err := log.Put(slotKey(i), b); err != nil {
+				return err
+			}
+		}
+		b, err := encodeRecord(lease)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(bucketLease).Put(keyLease, b)
+	})
+}
+
+func (s *BoltStorage) Load() (map[int]*Slot, *Lease, error) {
+	log, lease := map[int]*Slot{}, (*Lease)(nil)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+
+		b := tx.Bucket(bucketLease).Get(keyLease)
+		if b == nil {
+			return errNoState
+		} else if err := decodeRecord(s.recordName(bucketLease, keyLease), b, &lease); err != nil {
+			return err
+		}
+		return tx.Bucket(bucketLog).ForEach(func(k, v []byte) error {
+			slot := &Slot{}
+			if err := decodeRecord(s.recordName(bucketLog, k), v, slot); err != nil {
+				return err
+			}
+			log[int(binary.BigEndian.Uint64(k))] = slot
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return log, lease, nil
+}
+
+func (s *BoltStorage) SaveSnapshot(snap *Snapshot) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+
+		b, err := encodeRecord(snap)
+		if err != nil {
+			return err
+		} else if err := tx.Bucket(bucketSnapshot).Put(keySnapshot, b); err != nil {
+			return err
+		}
+		/* Keys are ordered as slots, so compacted slots come first. */
+		c, last := tx.Bucket(bucketLog).Cursor(), slotKey(snap.Slot)
+		for k, _ := c.First(); k != nil && bytes.Compare(k, last) <= 0; k, _ = c.First() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BoltStorage) LoadSnapshot() (*Snapshot, error) {
+	var snap *Snapshot
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketSnapshot).Get(keySnapshot)
+		if b == nil {
+			return nil
+		}
+		return decodeRecord(s.recordName(bucketSnapshot, keySnapshot), b, &snap)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+/* Return name of record under key in bucket, for errors.
+ */
+func (s *BoltStorage) recordName(bucket, key []byte) string {
+	if bytes.Equal(bucket, bucketLog) && len(key) == len(slotKey(0)) {
+		return fmt.Sprintf("%s:%s/%d", s.db.Path(), bucket, binary.BigEndian.Uint64(key))
+	}
+	return fmt.Sprintf("%s:%s/%s", s.db.Path(), bucket, key)
+}
+
+func (s *BoltStorage) Path() string {
+	return s.db.Path()
+}
+
+func (s *BoltStorage) Close() error {
+	return s.db.Close()
+}
+
+func (s *BoltStorage) Remove() error {
+	path := s.db.Path()
+	if err := s.db.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
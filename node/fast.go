@@ -0,0 +1,233 @@
+package paxos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/marius-j-i/paxos/util"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	/* Errors. */
+	errFastDisabled    = errors.New("fast paxos disabled; [%s] unavailable")
+	errFastQuorumKind  = errors.New("fast paxos requires a counting quorum system, not [%T]")
+	errFastQuorumSize  = errors.New("fast quorum [%d] not within [1, %d] accepters")
+	errFastIntersect   = errors.New("phase-1 quorum [%d] and two fast quorums [%d] do not intersect among [%d] accepters")
+	errRecoveryFailure = errors.New("recovery of slot [%d] failed after [%d] attempts")
+
+	/* Globals. */
+	fastPaxos  = false // on true; accepters accept values sent directly from clients in a fast round
+	fastQuorum = 0     // accepters needed to choose a value in a fast round; zero is the smallest valid size
+
+	/* Ballot of fast rounds.
+	 * Lower than any ballot a proposer issues, so every slot starts promised to it. */
+	fastBallot = Ballot{Round: 1, ID: 0}
+)
+
+/* Set accepters to accept values sent directly from clients in a fast round.
+ * Fast rounds are refused for slots covered by a lease, so leader election
+ * should be disabled along with enabling fast paxos.
+ */
+func SetFastPaxos(trueOrFalse bool) {
+	fastPaxos = trueOrFalse
+}
+
+/* Set fast quorum size for nodes created hereafter.
+ * Size must satisfy q1 + 2*fast > 2*accepters, where q1 is the phase-1 quorum size.
+ */
+func SetFastQuorum(size int) {
+	fastQuorum = size
+}
+
+/* Return fast quorum size for counting quorum system among accepters,
+ * and assert any phase-1 quorum intersects any two fast quorums.
+ */
+func newFastQuorum(quorums QuorumSystem, accepters int) (int, error) {
+
+	counting, ok := quorums.(*Counting)
+	if !ok {
+		/* Only an error if fast rounds are to be used. */
+		if fastPaxos {
+			return 0, util.ErrorFormat(errFastQuorumKind, quorums)
+		}
+		return 0, nil
+	}
+	fast := fastQuorum
+	if fast == 0 {
+		fast = (2*accepters-counting.Q1)/2 + 1
+	}
+	if fast < 1 || fast > accepters {
+		return 0, util.ErrorFormat(errFastQuorumSize, fast, accepters)
+	} else if counting.Q1+2*fast <= 2*accepters {
+		return 0, util.ErrorFormat(errFastIntersect, counting.Q1, fast, accepters)
+	}
+	return fast, nil
+}
+
+/* Return address of proposer coordinating recovery of collided fast rounds.
+ * Every member agrees on the proposer with the lowest address.
+ */
+func (n *Node) coordinator() string {
+	coordinator := ``
+	if n.is(Proposer) {
+		coordinator = n.server.Addr
+	}
+	for addr, role := range n.members() {
+		if role == Proposer && (coordinator == `` || addr < coordinator) {
+			coordinator = addr
+		}
+	}
+	return coordinator
+}
+
+/* /fast
+ * Role - Accepter
+ */
+
+func (n *Node) PostFast(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	/* Get slot from url. */
+	slot, err := n.getVarInt(req, varSlot)
+	if err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	/* Assert Role for slot. */
+	if !n.isAt(Accepter, slot) {
+		err := util.ErrorFormat(errWrongNodeType, "accepter", req.URL)
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	} else if !fastPaxos {
+		err := util.ErrorFormat(errFastDisabled, req.URL)
+		n.respondError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	/* Get value from body. */
+	v, code, err := n.getBodyValue(w, req)
+	if err != nil {
+		n.respondError(w, code, err.Error())
+		return
+	}
+	p, accepted, err := n.acceptFast(slot, v)
+	if err != nil {
+		n.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if accepted {
+		/* Notify learners, and coordinator of fast round. */
+		go n.notifyLearners(slot, fastBallot, KindValue, v)
+	}
+	/* Respond with appropriate promise. */
+	if err := json.NewEncoder(w).Encode(&p); err != nil {
+		n.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+}
+
+/* Accept first value v of fast round for slot, unless promised to a classic round.
+ * Decision and its persistence are atomic.
+ * Return promise describing slot after decision, and true if accepted.
+ */
+func (n *Node) acceptFast(slot int, v []byte) (*Promise, bool, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	/* Compacted slots were chosen; refuse any new value for them. */
+	if slot <= n.snapshotSlotLocked() {
+		return newPromise().setNode(n, newSlot(), slot), false, nil
+	}
+	s := n.slotLocked(slot)
+	if s.Prepare.Greater(fastBallot) || !s.N.IsZero() {
+		log.Infof("reject fast value [%s] in favor of prepare proposal [%s] for slot [%d]",
+			v, s.Prepare, slot)
+		return newPromise().setNode(n, s, slot), false, nil
+	}
+	/* Accept fast value, which implies a promise to fast round. */
+	s.Prepare, s.N, s.Value, s.Kind = fastBallot, fastBallot, v, KindValue
+	if err := n.persistLocked(slot); err != nil {
+		return nil, false, err
+	}
+	return newPromise().setNode(n, s, slot), true, nil
+}
+
+/* Return true if no value can gather a fast quorum from votes in a fast round
+ * among accepters of configuration, along with the most popular value.
+ */
+func (c *Config) collided(votes map[string]Slot) (bool, []byte) {
+
+	counts, cast := map[string]int{}, 0
+	for _, s := range votes {
+		if s.N == fastBallot {
+			counts[string(s.Value)]++
+			cast++
+		}
+	}
+	most, popular := 0, ``
+	for v, c := range counts {
+		if c > most || (c == most && v < popular) {
+			most, popular = c, v
+		}
+	}
+	/* Even if every remaining accepter voted for most popular value. */
+	return most+(c.LenRoles(Accepter)-cast) < c.Fast, []byte(popular)
+}
+
+/* Return value for proposal recovering a fast round, from how many of
+ * promised accepters accepted each value in the fast round.
+ * Value possibly chosen in fast round is unique and must be proposed,
+ * otherwise most popular value is proposed.
+ */
+func (c *Config) fastValue(counts map[string]int, promised int) []byte {
+
+	values := make([]string, 0, len(counts))
+	for v := range counts {
+		values = append(values, v)
+	}
+	/* Most popular value first; ties broken by value for determinism. */
+	sort.Slice(values, func(i, j int) bool {
+		if counts[values[i]] != counts[values[j]] {
+			return counts[values[i]] > counts[values[j]]
+		}
+		return values[i] < values[j]
+	})
+	for _, v := range values {
+		/* Chosen if accepters that did not promise all voted for value. */
+		if counts[v]+(c.LenRoles(Accepter)-promised) >= c.Fast {
+			return []byte(v)
+		}
+	}
+	return []byte(values[0])
+}
+
+/* Coordinator recovers slot after collision in fast round with a classic round.
+ * Prepare-phase finds any value possibly chosen in fast round, which is
+ * then proposed in accept-phase; otherwise value v is proposed.
+ */
+func (n *Node) recover(slot int, v []byte) error {
+
+	/* Single recovery of slot at a time. */
+	if !n.reserve(slot) {
+		return nil
+	}
+	defer n.releaseSlot(slot)
+
+	for try := 0; try < maxProposals; try++ {
+		b := n.newBallot(slot)
+		quorum, bPrime, kPrime, vPrime := n.Prepare(context.Background(), slot, b, KindValue, v)
+		if !quorum {
+			n.retry(slot, b, bPrime)
+			continue
+		}
+		if accepted, bReject := n.Accept(context.Background(), slot, b, kPrime, vPrime); !accepted {
+			n.retry(slot, b, bReject)
+			continue
+		}
+		log.Infof("recovered slot [%d] with value [%s] after collision", slot, vPrime)
+		return n.choose(slot, b, kPrime, vPrime)
+	}
+	return util.ErrorFormat(errRecoveryFailure, slot, maxProposals)
+}
@@ -0,0 +1,680 @@
+package paxos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marius-j-i/paxos/paxospb"
+	"github.com/marius-j-i/paxos/util"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	/* Errors. */
+	errMemberArg = errors.New("invalid member [%s] or role [%s] for [%s]")
+
+	/* gRPC. */
+	contentTypeGrpc = "application/grpc"
+	trailerStatus   = "paxos-status" // trailer carrying HTTP status code of a proposal outcome
+	methodPropose   = "/paxos.Paxos/Propose"
+	methodChange    = "/paxos.Paxos/ChangeMember"
+
+	/* Arguments of requests, matched whole as by mux routes. */
+	matchAddr = regexp.MustCompile(`^(` + regexAddr + `)$`)
+	matchRole = regexp.MustCompile(`^(` + regexRole + `)$`)
+)
+
+/* Transport carrying messages between members as gRPC calls.
+ * The gRPC service shares the port of the HTTP API, which stays available.
+ */
+type GrpcTransport struct {
+	*HttpTransport                             // binds listeners, and serves HTTP API
+	conns          map[string]*grpc.ClientConn // address mapping to connection to member
+	servers        map[string]*grpc.Server     // address mapping to gRPC server of served node
+	mu             sync.Mutex                  // guards conns and servers
+}
+
+/* Return new gRPC transport.
+ */
+func NewGrpcTransport() *GrpcTransport {
+	return &GrpcTransport{
+		HttpTransport: NewHttpTransport(),
+		conns:         map[string]*grpc.ClientConn{},
+		servers:       map[string]*grpc.Server{},
+	}
+}
+
+/* Return new gRPC transport, where members authenticate each other with
+ * certificates issued by cluster CA in credentials.
+ */
+func NewGrpcTransportTLS(c *Credentials) *GrpcTransport {
+	t := NewGrpcTransport()
+	t.HttpTransport = NewHttpTransportTLS(c)
+	return t
+}
+
+/* Return stub for member at address, connecting on first use.
+ */
+func (t *GrpcTransport) paxos(addr string) (paxospb.PaxosClient, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	conn, ok := t.conns[addr]
+	if !ok {
+		creds := insecure.NewCredentials()
+		if t.creds != nil {
+			creds = credentials.NewTLS(t.creds.ClientConfig())
+		}
+		c, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, err
+		}
+		t.conns[addr], conn = c, c
+	}
+	return paxospb.NewPaxosClient(conn), nil
+}
+
+func (t *GrpcTransport) Prepare(ctx context.Context, addr string, slot int, b Ballot) (*Promise, error) {
+	c, err := t.paxos(addr)
+	if err != nil {
+		return nil, err
+	}
+	p, err := c.Prepare(outgoingContext(ctx), &paxospb.PrepareRequest{
+		Slot:   int64(slot),
+		Ballot: pbBallot(b),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromPbPromise(p), nil
+}
+
+func (t *GrpcTransport) Accept(ctx context.Context, addr string, slot int, b Ballot, k Kind, v []byte) (*Promise, error) {
+	c, err := t.paxos(addr)
+	if err != nil {
+		return nil, err
+	}
+	p, err := c.Accept(outgoingContext(ctx), &paxospb.AcceptRequest{
+		Slot:   int64(slot),
+		Ballot: pbBallot(b),
+		Value:  v,
+		Kind:   paxospb.Kind(k),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromPbPromise(p), nil
+}
+
+func (t *GrpcTransport) Accepted(addr, from string, slot int, b Ballot, k Kind, v []byte) error {
+	c, err := t.paxos(addr)
+	if err != nil {
+		return err
+	}
+	_, err = c.Accepted(context.Background(), &paxospb.AcceptedRequest{
+		From:   from,
+		Slot:   int64(slot),
+		Ballot: pbBallot(b),
+		Value:  v,
+		Kind:   paxospb.Kind(k),
+	})
+	return err
+}
+
+func (t *GrpcTransport) Lease(addr, proposer string, slot int, b Ballot) (*Grant, error) {
+	c, err := t.paxos(addr)
+	if err != nil {
+		return nil, err
+	}
+	g, err := c.Lease(context.Background(), &paxospb.LeaseRequest{
+		Proposer: proposer,
+		Slot:     int64(slot),
+		Ballot:   pbBallot(b),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromPbGrant(g), nil
+}
+
+func (t *GrpcTransport) Configure(addr string, slot int, v []byte) error {
+	c, err := t.paxos(addr)
+	if err != nil {
+		return err
+	}
+	_, err = c.Configure(context.Background(), &paxospb.ConfigureRequest{
+		Slot:  int64(slot),
+		Value: v,
+	})
+	return err
+}
+
+/* Slot is streamed by GetAccepted, unless absent from log of accepter.
+ */
+func (t *GrpcTransport) Peek(addr string, slot int) (*Promise, error) {
+	c, err := t.paxos(addr)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := c.GetAccepted(context.Background(), &paxospb.GetAcceptedRequest{
+		From: int64(slot),
+		To:   int64(slot),
+	})
+	if err != nil {
+		return nil, err
+	}
+	p := newPromise()
+	p.From, p.Slot = addr, slot
+	entry, err := stream.Recv()
+	if err == io.EOF {
+		return p, nil
+	} else if err != nil {
+		return nil, err
+	}
+	p.N, p.Prepare, p.Value, p.Kind = fromPbBallot(entry.GetProposal()), fromPbBallot(entry.GetPrepare()), entry.GetAccepted(), Kind(entry.GetKind())
+	return p, nil
+}
+
+/* Leader reports status code of proposal outcome in a trailer; a missing
+ * trailer means leader was never reached. Membership changes are forwarded
+ * to ChangeMember of leader, since Propose only proposes values.
+ */
+func (t *GrpcTransport) Forward(ctx context.Context, addr, from string, k Kind, v []byte) (int, *Proposal, error) {
+	c, err := t.paxos(addr)
+	if err != nil {
+		return 0, nil, err
+	}
+	var trailer metadata.MD
+	var p *paxospb.Proposal
+	if k == KindChange {
+		var ch *Change
+		if ch, err = decodeChange(v); err != nil {
+			return 0, nil, err
+		}
+		p, err = c.ChangeMember(outgoingContext(ctx), &paxospb.ChangeRequest{
+			Member:      ch.Addr,
+			Role:        ch.roleName(),
+			ForwardedBy: from,
+		}, grpc.Trailer(&trailer))
+	} else {
+		p, err = c.Propose(outgoingContext(ctx), &paxospb.ProposeRequest{
+			Value:       v,
+			ForwardedBy: from,
+		}, grpc.Trailer(&trailer))
+	}
+
+	code := 0
+	if s := trailer.Get(trailerStatus); len(s) > 0 {
+		code, _ = strconv.Atoi(s[0])
+	}
+	if err != nil {
+		return code, nil, errors.New(status.Convert(err).Message())
+	}
+	return code, fromPbProposal(p), nil
+}
+
+func (t *GrpcTransport) Snapshot(addr string) (*Snapshot, error) {
+	c, err := t.paxos(addr)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := c.GetSnapshot(context.Background(), &paxospb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	return fromPbSnapshot(snap), nil
+}
+
+func (t *GrpcTransport) Ping(addr string) error {
+	c, err := t.paxos(addr)
+	if err != nil {
+		return err
+	}
+	_, err = c.Ping(context.Background(), &paxospb.Empty{})
+	return err
+}
+
+/* Serve gRPC service and HTTP API of node on the same port.
+ * HTTP/2 requests with gRPC content-type go to the gRPC server, others to the API.
+ */
+func (t *GrpcTransport) Serve(n *Node) error {
+
+	s := grpc.NewServer()
+	paxospb.RegisterPaxosServer(s, &grpcServer{n: n})
+
+	t.mu.Lock()
+	t.servers[n.server.Addr] = s
+	t.mu.Unlock()
+
+	api := n.server.Handler
+	mux := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.ProtoMajor == 2 && strings.HasPrefix(req.Header.Get("Content-Type"), contentTypeGrpc) {
+			s.ServeHTTP(w, req)
+		} else {
+			api.ServeHTTP(w, req)
+		}
+	})
+	/* Without TLS, gRPC clients speak HTTP/2 in cleartext. */
+	n.server.Handler = h2c.NewHandler(mux, &http2.Server{})
+
+	return t.HttpTransport.Serve(n)
+}
+
+/* Stop gRPC server of node, drop connection to it, and shutdown its HTTP server.
+ */
+func (t *GrpcTransport) Close(n *Node) error {
+
+	t.mu.Lock()
+	s := t.servers[n.server.Addr]
+	conn := t.conns[n.server.Addr]
+	delete(t.servers, n.server.Addr)
+	delete(t.conns, n.server.Addr)
+	t.mu.Unlock()
+
+	if s != nil {
+		s.Stop()
+	}
+	if conn != nil {
+		conn.Close()
+	}
+	return t.HttpTransport.Close(n)
+}
+
+/* gRPC service of node, mirroring its HTTP API.
+ */
+type grpcServer struct {
+	paxospb.UnimplementedPaxosServer
+	n *Node // node served
+}
+
+func (s *grpcServer) Prepare(ctx context.Context, req *paxospb.PrepareRequest) (*paxospb.Promise, error) {
+
+	if !s.n.isAt(Accepter, int(req.GetSlot())) {
+		return nil, grpcError(http.StatusBadRequest, util.ErrorFormat(errWrongNodeType, "accepter", PostPrepare))
+	}
+	p, err := s.n.prepare(incomingContext(ctx), int(req.GetSlot()), fromPbBallot(req.GetBallot()))
+	if err != nil {
+		return nil, grpcError(http.StatusInternalServerError, err)
+	}
+	return pbPromise(p), nil
+}
+
+func (s *grpcServer) Accept(ctx context.Context, req *paxospb.AcceptRequest) (*paxospb.Promise, error) {
+
+	if !s.n.isAt(Accepter, int(req.GetSlot())) {
+		return nil, grpcError(http.StatusBadRequest, util.ErrorFormat(errWrongNodeType, "accepter", PostAccept))
+	} else if code, err := checkValue(req.GetValue(), PostAccept); err != nil {
+		return nil, grpcError(code, err)
+	}
+	slot, b, k, v := int(req.GetSlot()), fromPbBallot(req.GetBallot()), Kind(req.GetKind()), req.GetValue()
+	p, accepted, err := s.n.accept(incomingContext(ctx), slot, b, k, v)
+	if err != nil {
+		return nil, grpcError(http.StatusInternalServerError, err)
+	} else if accepted {
+		/* Notify learners of acceptance. */
+		go s.n.notifyLearners(slot, b, k, v)
+	}
+	return pbPromise(p), nil
+}
+
+func (s *grpcServer) Accepted(ctx context.Context, req *paxospb.AcceptedRequest) (*paxospb.Empty, error) {
+
+	slot := int(req.GetSlot())
+	if err := s.n.learnsFrom(req.GetFrom(), slot); err != nil {
+		return nil, grpcError(http.StatusBadRequest, err)
+	} else if code, err := checkValue(req.GetValue(), PostLearn); err != nil {
+		return nil, grpcError(code, err)
+	}
+	/* Count acceptance towards quorum. */
+	if err := s.n.learn(req.GetFrom(), slot, fromPbBallot(req.GetBallot()), Kind(req.GetKind()), req.GetValue()); err != nil {
+		return nil, grpcError(http.StatusInternalServerError, err)
+	}
+	return &paxospb.Empty{}, nil
+}
+
+func (s *grpcServer) Lease(ctx context.Context, req *paxospb.LeaseRequest) (*paxospb.Grant, error) {
+
+	if !s.n.isAt(Accepter, int(req.GetSlot())) {
+		return nil, grpcError(http.StatusBadRequest, util.ErrorFormat(errWrongNodeType, "accepter", PostLease))
+	}
+	g, err := s.n.grantLease(req.GetProposer(), int(req.GetSlot()), fromPbBallot(req.GetBallot()))
+	if err != nil {
+		return nil, grpcError(http.StatusInternalServerError, err)
+	}
+	return pbGrant(g), nil
+}
+
+func (s *grpcServer) Configure(ctx context.Context, req *paxospb.ConfigureRequest) (*paxospb.Empty, error) {
+
+	if code, err := checkValue(req.GetValue(), PostConfigure); err != nil {
+		return nil, grpcError(code, err)
+	} else if _, err := decodeChange(req.GetValue()); err != nil {
+		return nil, grpcError(http.StatusBadRequest, err)
+	}
+	if err := s.n.configure(int(req.GetSlot()), req.GetValue()); err != nil {
+		return nil, grpcError(http.StatusNotFound, err)
+	}
+	return &paxospb.Empty{}, nil
+}
+
+func (s *grpcServer) Ping(ctx context.Context, req *paxospb.Empty) (*paxospb.Empty, error) {
+	return &paxospb.Empty{}, nil
+}
+
+/* Return latest snapshot of member, for lagging members to catch up from.
+ */
+func (s *grpcServer) GetSnapshot(ctx context.Context, req *paxospb.Empty) (*paxospb.Snapshot, error) {
+
+	snap := s.n.latestSnapshot()
+	if snap == nil {
+		return nil, grpcError(http.StatusNotFound, util.ErrorFormat(errNoSnapshot, s.n.server.Addr))
+	}
+	return pbSnapshot(snap), nil
+}
+
+/* Status code of outcome is reported in a trailer, for proposers forwarding to leader.
+ */
+func (s *grpcServer) Propose(ctx context.Context, req *paxospb.ProposeRequest) (*paxospb.Proposal, error) {
+
+	code, p, err := s.propose(ctx, KindValue, req.GetValue(), req.GetForwardedBy() != ``, methodPropose)
+	if err := grpc.SetTrailer(ctx, metadata.Pairs(trailerStatus, strconv.Itoa(code))); err != nil {
+		return nil, grpcError(http.StatusInternalServerError, err)
+	}
+	if err != nil {
+		return nil, grpcError(code, err)
+	}
+	return pbProposal(p), nil
+}
+
+/* Values are proposed in order received; stream ends on first value not chosen.
+ */
+func (s *grpcServer) ProposeStream(stream grpc.BidiStreamingServer[paxospb.ProposeRequest, paxospb.Proposal]) error {
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		code, p, err := s.propose(stream.Context(), KindValue, req.GetValue(), req.GetForwardedBy() != ``, methodPropose)
+		if err != nil {
+			return grpcError(code, err)
+		} else if err := stream.Send(pbProposal(p)); err != nil {
+			return err
+		}
+	}
+}
+
+/* Propose value v of kind k on node, as /propose and /members do, in trace context of ctx.
+ */
+func (s *grpcServer) propose(ctx context.Context, k Kind, v []byte, forwarded bool, method string) (int, *Proposal, error) {
+
+	if !s.n.is(Proposer) {
+		return http.StatusBadRequest, nil, util.ErrorFormat(errWrongNodeType, "proposer", method)
+	} else if code, err := checkValue(v, method); err != nil {
+		return code, nil, err
+	}
+	return s.n.proposeValue(incomingContext(ctx), k, v, forwarded)
+}
+
+func (s *grpcServer) GetAccepted(req *paxospb.GetAcceptedRequest, stream grpc.ServerStreamingServer[paxospb.Entry]) error {
+
+	slots, err := s.n.slots(int(req.GetFrom()), int(req.GetTo()))
+	if err != nil {
+		return grpcError(http.StatusBadRequest, err)
+	}
+	for _, i := range slots {
+		slot := s.n.peekSlot(i)
+		entry := &paxospb.Entry{
+			Slot:     int64(i),
+			Accepted: slot.Value,
+			Proposal: pbBallot(slot.N),
+			Prepare:  pbBallot(slot.Prepare),
+			Kind:     paxospb.Kind(slot.Kind),
+		}
+		if err := stream.Send(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *grpcServer) GetMembers(ctx context.Context, req *paxospb.Empty) (*paxospb.Members, error) {
+
+	c := s.n.config(s.n.nextSlot())
+	members := map[string]Role{}
+	for addr, r := range c.Network {
+		members[addr] = r
+	}
+	if c.Role != 0 {
+		members[s.n.server.Addr] = c.Role
+	}
+	resp := &paxospb.Members{}
+	for addr, r := range members {
+		resp.Members = append(resp.Members, &paxospb.Member{Addr: addr, Role: roleNames[r], Id: int64(c.IDs[addr])})
+	}
+	sort.Slice(resp.Members, func(i, j int) bool {
+		return resp.Members[i].Addr < resp.Members[j].Addr
+	})
+	return resp, nil
+}
+
+func (s *grpcServer) ChangeMember(ctx context.Context, req *paxospb.ChangeRequest) (*paxospb.Proposal, error) {
+
+	addr, name := req.GetMember(), req.GetRole()
+	if !matchAddr.MatchString(addr) || !matchRole.MatchString(name) {
+		return nil, grpcError(http.StatusBadRequest, util.ErrorFormat(errMemberArg, addr, name, methodChange))
+	}
+	ch := s.n.newChange(addr, parseRole(name))
+	if err := s.n.checkChange(ch); err != nil {
+		return nil, grpcError(http.StatusBadRequest, err)
+	}
+	v, err := ch.encode()
+	if err != nil {
+		return nil, grpcError(http.StatusInternalServerError, err)
+	}
+	/* Change is chosen as any other value, but of its own kind. */
+	code, p, err := s.propose(ctx, KindChange, v, req.GetForwardedBy() != ``, methodChange)
+	if err := grpc.SetTrailer(ctx, metadata.Pairs(trailerStatus, strconv.Itoa(code))); err != nil {
+		return nil, grpcError(http.StatusInternalServerError, err)
+	}
+	if err != nil {
+		return nil, grpcError(code, err)
+	}
+	return pbProposal(p), nil
+}
+
+/* Return status code and error if value v is empty or exceeds maxValueSize.
+ */
+func checkValue(v []byte, method string) (int, error) {
+	if int64(len(v)) > maxValueSize {
+		return http.StatusRequestEntityTooLarge, util.ErrorFormat(errValueSize, method, maxValueSize)
+	} else if len(v) == 0 {
+		return http.StatusBadRequest, util.ErrorFormat(errNoValue, method, varValue)
+	}
+	return http.StatusOK, nil
+}
+
+/* Return gRPC status error with code equivalent of HTTP status code.
+ */
+func grpcError(code int, err error) error {
+	c := codes.Unknown
+	switch code {
+	case http.StatusBadRequest:
+		c = codes.InvalidArgument
+	case http.StatusNotFound:
+		c = codes.NotFound
+	case http.StatusConflict:
+		c = codes.Aborted
+	case http.StatusRequestEntityTooLarge:
+		c = codes.ResourceExhausted
+	case http.StatusInternalServerError:
+		c = codes.Internal
+	case http.StatusServiceUnavailable:
+		c = codes.Unavailable
+	}
+	return status.Error(c, err.Error())
+}
+
+/* Conversions between messages of node and of gRPC service.
+ */
+
+func pbBallot(b Ballot) *paxospb.Ballot {
+	return &paxospb.Ballot{Round: int64(b.Round), Id: int64(b.ID)}
+}
+
+func fromPbBallot(b *paxospb.Ballot) Ballot {
+	return Ballot{Round: int(b.GetRound()), ID: int(b.GetId())}
+}
+
+func pbLease(l *Lease) *paxospb.Lease {
+	if l == nil {
+		return nil
+	}
+	return &paxospb.Lease{
+		Leader:  l.Leader,
+		Ballot:  pbBallot(l.Ballot),
+		Slot:    int64(l.Slot),
+		Expires: l.Expires.UnixNano(),
+	}
+}
+
+func fromPbLease(l *paxospb.Lease) *Lease {
+	if l == nil {
+		return nil
+	}
+	return &Lease{
+		Leader:  l.GetLeader(),
+		Ballot:  fromPbBallot(l.GetBallot()),
+		Slot:    int(l.GetSlot()),
+		Expires: time.Unix(0, l.GetExpires()),
+	}
+}
+
+func pbPromise(p *Promise) *paxospb.Promise {
+	return &paxospb.Promise{
+		From:      p.From,
+		Slot:      int64(p.Slot),
+		N:         pbBallot(p.N),
+		Prepare:   pbBallot(p.Prepare),
+		Value:     p.Value,
+		Kind:      paxospb.Kind(p.Kind),
+		Lease:     pbLease(p.Lease),
+		Compacted: int64(p.Compacted),
+	}
+}
+
+func fromPbPromise(p *paxospb.Promise) *Promise {
+	promise := newPromise()
+	promise.From = p.GetFrom()
+	promise.Slot = int(p.GetSlot())
+	promise.N = fromPbBallot(p.GetN())
+	promise.Prepare = fromPbBallot(p.GetPrepare())
+	promise.Value = p.GetValue()
+	promise.Kind = Kind(p.GetKind())
+	promise.Lease = fromPbLease(p.GetLease())
+	promise.Compacted = int(p.GetCompacted())
+	return promise
+}
+
+func pbGrant(g *Grant) *paxospb.Grant {
+	grant := &paxospb.Grant{
+		From:    g.From,
+		Lease:   pbLease(g.Lease),
+		Prepare: pbBallot(g.Prepare),
+	}
+	for _, p := range g.Accepted {
+		grant.Accepted = append(grant.Accepted, pbPromise(p))
+	}
+	return grant
+}
+
+func fromPbGrant(g *paxospb.Grant) *Grant {
+	grant := &Grant{
+		From:    g.GetFrom(),
+		Lease:   fromPbLease(g.GetLease()),
+		Prepare: fromPbBallot(g.GetPrepare()),
+	}
+	for _, p := range g.GetAccepted() {
+		grant.Accepted = append(grant.Accepted, fromPbPromise(p))
+	}
+	return grant
+}
+
+func pbProposal(p *Proposal) *paxospb.Proposal {
+	return &paxospb.Proposal{
+		Slot:    int64(p.Slot),
+		Ballot:  pbBallot(p.Ballot),
+		Value:   p.Value,
+		Adopted: p.Adopted,
+		Kind:    paxospb.Kind(p.Kind),
+		Filled:  pbSlots(p.Filled),
+	}
+}
+
+func fromPbProposal(p *paxospb.Proposal) *Proposal {
+	return &Proposal{
+		Slot:    int(p.GetSlot()),
+		Ballot:  fromPbBallot(p.GetBallot()),
+		Value:   p.GetValue(),
+		Kind:    Kind(p.GetKind()),
+		Adopted: p.GetAdopted(),
+		Filled:  fromPbSlots(p.GetFilled()),
+	}
+}
+
+func pbSlots(slots []int) []int64 {
+	pb := make([]int64, 0, len(slots))
+	for _, i := range slots {
+		pb = append(pb, int64(i))
+	}
+	return pb
+}
+
+func fromPbSlots(pb []int64) []int {
+	slots := make([]int, 0, len(pb))
+	for _, i := range pb {
+		slots = append(slots, int(i))
+	}
+	return slots
+}
+
+func pbSnapshot(s *Snapshot) *paxospb.Snapshot {
+	snap := &paxospb.Snapshot{
+		Slot:    int64(s.Slot),
+		State:   s.State,
+		Changes: map[int64]*paxospb.Member{},
+	}
+	for i, ch := range s.Changes {
+		snap.Changes[int64(i)] = &paxospb.Member{Addr: ch.Addr, Role: roleNames[ch.Role], Id: int64(ch.ID)}
+	}
+	return snap
+}
+
+func fromPbSnapshot(s *paxospb.Snapshot) *Snapshot {
+	snap := &Snapshot{
+		Slot:    int(s.GetSlot()),
+		State:   s.GetState(),
+		Changes: map[int]*Change{},
+	}
+	for i, m := range s.GetChanges() {
+		snap.Changes[int(i)] = &Change{Addr: m.GetAddr(), Role: parseRole(m.GetRole()), ID: int(m.GetId())}
+	}
+	return snap
+}
@@ -0,0 +1,414 @@
+package paxos
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/marius-j-i/paxos/util"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	/* Globals. */
+	leaderElection = true // on true; proposers elect a leader that skips prepare-phase
+
+	/* Lease. */
+	leaseDuration = 2 * time.Second        // time accepters grant leader a lease for
+	leaseGuard    = 200 * time.Millisecond // leader stops trusting lease this long before expiry
+
+	/* Header on proposals forwarded to leader; forwarded proposals are never forwarded again. */
+	headerForwarded = "Paxos-Forwarded-By"
+)
+
+/* Promise from an accepter to a leader for every slot from an index onwards.
+ * Accepters refuse prepares from other proposers until the lease expires.
+ */
+type Lease struct {
+	Leader  string    `json:"leader"`  // address of proposer holding lease
+	Ballot  Ballot    `json:"ballot"`  // ballot promised for every slot from Slot onwards
+	Slot    int       `json:"slot"`    // first slot lease applies to
+	Expires time.Time `json:"expires"` // lease is void after expiry
+}
+
+/* Response to /lease from accepters.
+ */
+type Grant struct {
+	From     string     `json:"from"`     // url to response member
+	Lease    *Lease     `json:"lease"`    // valid lease accepter holds for a leader, if any
+	Prepare  Ballot     `json:"prepare"`  // highest ballot promised for slots from requested slot onwards
+	Accepted []*Promise `json:"accepted"` // accepted values for slots from requested slot onwards
+	err      error      // non-nil if unsuccessful POST
+}
+
+/* Set proposers to elect a leader, as opposed to every proposal running prepare-phase.
+ */
+func SetLeaderElection(trueOrFalse bool) {
+	leaderElection = trueOrFalse
+}
+
+/* Set time accepters grant leader a lease for.
+ */
+func SetLeaseDuration(d time.Duration) {
+	leaseDuration = d
+}
+
+/* Return true if lease is held and not expired.
+ */
+func (l *Lease) valid() bool {
+	return l != nil && time.Now().Before(l.Expires)
+}
+
+/* Return true if node holds a valid lease as leader.
+ */
+func (n *Node) isLeader() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.lease.valid() && n.lease.Leader == n.server.Addr
+}
+
+/* Return true if lease held covers slot; slots before a lease were never promised to it.
+ */
+func (n *Node) leases(slot int) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.lease != nil && slot >= n.lease.Slot
+}
+
+/* Return address of another proposer known to hold a valid lease, or
+ * return "" if no other leader is known.
+ */
+func (n *Node) leader() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !n.lease.valid() || n.lease.Leader == n.server.Addr {
+		return ``
+	}
+	return n.lease.Leader
+}
+
+/* Return true if a valid lease is held by another proposer than that of ballot b.
+ * Caller holds n.mu.
+ */
+func (n *Node) leasedToOther(b Ballot) bool {
+	return n.lease.valid() && n.lease.Ballot.ID != b.ID
+}
+
+/* Record lease as held by self or learned from accepters.
+ */
+func (n *Node) setLease(l *Lease) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.lease = l
+}
+
+/* Step down as leader, or forget a learned leader.
+ */
+func (n *Node) dropLease() {
+	n.setLease(nil)
+}
+
+/* /lease
+ * Role - Accepter
+ */
+
+func (n *Node) PostLease(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	/* Get proposer, slot and ballot from url. */
+	proposer, err := n.getVarString(req, varProposer)
+	if err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	slot, err := n.getVarInt(req, varSlot)
+	if err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	/* Assert Role for slots leased. */
+	if !n.isAt(Accepter, slot) {
+		err := util.ErrorFormat(errWrongNodeType, "accepter", req.URL)
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	b, err := n.getVarBallot(req, varBallot)
+	if err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	g, err := n.grantLease(proposer, slot, b)
+	if err != nil {
+		n.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	/* Respond with lease held, granted or not. */
+	if err := json.NewEncoder(w).Encode(g); err != nil {
+		n.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+}
+
+/* Grant proposer lease with ballot b for every slot from argument slot onwards,
+ * unless another proposer holds a valid lease, or any of those slots promised
+ * a higher ballot. Decision and its persistence are atomic.
+ * Return grant describing lease held after request.
+ */
+func (n *Node) grantLease(proposer string, slot int, b Ballot) (*Grant, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.metrics.received.WithLabelValues(phaseLease).Inc()
+
+	g := &Grant{
+		From:     n.server.Addr,
+		Lease:    nil,
+		Prepare:  Ballot{},
+		Accepted: []*Promise{},
+	}
+	promised := []int{}
+	/* Highest promise and accepted values from slot onwards. */
+	if n.lease != nil && n.lease.Slot >= slot {
+		g.Prepare = n.lease.Ballot
+	}
+	for i, s := range n.log {
+		if i < slot {
+			continue
+		}
+		if s.Prepare.Greater(g.Prepare) {
+			g.Prepare = s.Prepare
+		}
+		if !s.N.IsZero() {
+			g.Accepted = append(g.Accepted, &Promise{
+				From: n.server.Addr, Slot: i, N: s.N, Prepare: s.Prepare, Value: s.Value, Kind: s.Kind,
+			})
+		}
+	}
+	/* Another leader holds lease. */
+	held := n.lease.valid() && n.lease.Leader != proposer
+	if !held && !b.Less(g.Prepare) {
+		/* Renewal from same leader keeps earliest slot. */
+		if n.lease != nil && n.lease.Leader == proposer && n.lease.Slot < slot {
+			slot = n.lease.Slot
+		}
+		n.lease = &Lease{
+			Leader:  proposer,
+			Ballot:  b,
+			Slot:    slot,
+			Expires: time.Now().Add(leaseDuration),
+		}
+		/* Promise ballot for existing slots; new slots start promised by n.slot. */
+		for i, s := range n.log {
+			if i >= slot {
+				s.Prepare = b
+				promised = append(promised, i)
+			}
+		}
+		g.Prepare = b
+	} else {
+		n.metrics.rejected.WithLabelValues(phaseLease).Inc()
+	}
+	if n.lease.valid() {
+		l := *n.lease
+		g.Lease = &l
+	}
+	return g, n.persistLocked(promised...)
+}
+
+/* Return ballot of lease held as leader, electing self as leader if not.
+ * Return CREATED and ballot if proposer is leader, or
+ *
+ * return non-CREATED code from Elect otherwise.
+ */
+func (n *Node) lead(ctx context.Context, slot int) (int, Ballot, error) {
+
+	/* Lease only promises slots from where leader was elected.
+	 * Concurrent proposals wait for an ongoing election, rather than compete with it. */
+	if !n.isLeader() || !n.leases(slot) {
+		n.electing.Lock()
+		if !n.isLeader() || !n.leases(slot) {
+			if code, err := n.Elect(ctx, slot); err != nil || code != http.StatusCreated {
+				n.electing.Unlock()
+				return code, Ballot{}, err
+			}
+		}
+		n.electing.Unlock()
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	/* Lease may have been dropped by a concurrent proposal. */
+	if n.lease == nil {
+		return http.StatusServiceUnavailable, Ballot{}, nil
+	}
+	return http.StatusCreated, n.lease.Ballot, nil
+}
+
+/* Proposer attempts to become leader by acquiring a lease from a quorum of
+ * accepters for every slot from argument slot onwards, with a new ballot.
+ * Values accepted for those slots are adopted and re-proposed by new leader.
+ *
+ * Return CREATED if proposer is leader, or
+ *
+ * return CONFLICT if another proposer is leader, and learn its lease, or
+ *
+ * return CONFLICT or SERVICE UNAVAILABLE if a re-try is possible, or
+ *
+ * return respond code and error on terminating request error.
+ */
+func (n *Node) Elect(ctx context.Context, slot int) (int, error) {
+	c := n.config(slot)
+	grants := make(chan *Grant, len(c.Network))
+
+	/* New ballot for lease, and lease as leader sees it. */
+	b := n.newBallot(slot)
+
+	ctx, span := startSpan(ctx, "paxos.elect", slotAttributes(slot, b)...)
+	defer span.End()
+	lease := &Lease{
+		Leader:  n.server.Addr,
+		Ballot:  b,
+		Slot:    slot,
+		Expires: time.Now().Add(leaseDuration - leaseGuard),
+	}
+
+	/* Fan-out. */
+	n.electFanOut(c, slot, b, grants)
+
+	/* Fan-in. */
+	start := time.Now()
+	quorum, bPrime, other, accepted := n.electFanIn(c, b, grants)
+	n.metrics.waited(phaseLease, start, quorum)
+	span.SetAttributes(attribute.Bool("paxos.quorum", quorum))
+
+	if other != nil {
+		/* Another proposer is leader; forward proposals to it. */
+		span.SetAttributes(attribute.String("paxos.leader", other.Leader))
+		n.setLease(other)
+		return http.StatusConflict, nil
+
+	} else if !quorum {
+		/* Accepters promised to b' > b, or were unreachable. */
+		return n.retry(slot, b, bPrime), nil
+	}
+	n.setLease(lease)
+	log.Infof("[%s] elected leader with ballot [%s] from slot [%d]", n.server.Addr, b, slot)
+
+	/* Complete slots with accepted values under own ballot, in log order. */
+	for _, p := range accepted {
+		if ok, bReject := n.Accept(ctx, p.Slot, b, p.Kind, p.Value); !ok {
+			n.dropLease()
+			return n.retry(p.Slot, b, bReject), nil
+		}
+		if err := n.choose(p.Slot, b, p.Kind, p.Value); err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+	return http.StatusCreated, nil
+}
+
+/* Fan-out method for elect.
+ * Proposer concurrently POSTs lease request to accepters of configuration.
+ */
+func (n *Node) electFanOut(c *Config, slot int, b Ballot, grants chan *Grant) {
+
+	/* Go routine. */
+	lease := func(addr string) {
+		n.metrics.sent.WithLabelValues(phaseLease).Inc()
+		g, err := n.transport.Lease(addr, n.server.Addr, slot, b)
+		if err != nil {
+			g = &Grant{err: err}
+		}
+		grants <- g
+	}
+	/* Send lease to accepters. */
+	for addr, role := range c.Network {
+		if role != Accepter {
+			continue
+		}
+		go lease(addr)
+	}
+}
+
+/* Fan-in method for elect.
+ * Proposer gathers grants from accepters until a quorum granted lease with ballot b.
+ *
+ * Return (true, b, nil, accepted) if a quorum granted lease, where accepted
+ * holds the highest-ballot accepted value for each slot among grants,
+ * sorted by slot, or
+ *
+ * return (false, b, lease, nil) if any accepter holds lease for another leader,
+ * or
+ * return (false, g.Prepare, nil, nil) where g is the grant with the highest
+ * promised ballot, if no quorum granted lease.
+ */
+func (n *Node) electFanIn(c *Config, b Ballot, grants chan *Grant) (bool, Ballot, *Lease, []*Promise) {
+
+	promised := b
+	granted := map[string]bool{}
+	adopted := map[int]*Promise{}
+	for i := 0; i < c.LenRoles(Accepter); i++ {
+		g := <-grants
+		if g.err != nil {
+			log.Info(g.err)
+			continue
+		} else /* Another proposer holds lease. */ if g.Lease != nil && g.Lease.Leader != n.server.Addr {
+			n.metrics.rejections.WithLabelValues(phaseLease).Inc()
+			return false, b, g.Lease, nil
+
+		} else /* Promised to a higher proposal. */ if g.Lease == nil || g.Lease.Ballot != b {
+			n.metrics.rejections.WithLabelValues(phaseLease).Inc()
+			if g.Prepare.Greater(promised) {
+				promised = g.Prepare
+			}
+			continue
+		}
+		/* Accepter granted lease.
+		 * Adopt accepted value with highest ballot for each slot. */
+		for _, p := range g.Accepted {
+			if a, ok := adopted[p.Slot]; !ok || p.N.Greater(a.N) {
+				adopted[p.Slot] = p
+			}
+		}
+		granted[g.From] = true
+		/* End early if proposer attained phase-1 quorum. */
+		if c.Quorums.Phase1(granted) {
+			break
+		}
+	}
+	/* Elect phase complete? */
+	if !c.Quorums.Phase1(granted) {
+		return false, promised, nil, nil
+	}
+	accepted := make([]*Promise, 0, len(adopted))
+	for _, p := range adopted {
+		accepted = append(accepted, p)
+	}
+	sort.Slice(accepted, func(i, j int) bool {
+		return accepted[i].Slot < accepted[j].Slot
+	})
+	return true, b, nil, accepted
+}
+
+/* Forward proposal for value v of kind k to leader, in trace context of ctx.
+ * Return outcome of proposal from leader, or
+ *
+ * return zero code if leader was unreachable.
+ */
+func (n *Node) forward(ctx context.Context, leader string, k Kind, v []byte) (int, *Proposal, error) {
+
+	code, p, err := n.transport.Forward(ctx, leader, n.server.Addr, k, v)
+	if code == 0 {
+		/* Leader is gone; forget it. */
+		log.Info(err)
+		n.dropLease()
+	}
+	return code, p, err
+}
@@ -0,0 +1,202 @@
+package paxos
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/marius-j-i/paxos/util"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	/* Errors. */
+	errNotAccepter = errors.New("[%s] is not an accepter in network")
+	errNotChosen   = errors.New("no value chosen for slot [%d]")
+)
+
+/* /learn
+ * Role - Learner, or coordinator of fast rounds
+ */
+
+func (n *Node) PostLearn(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	/* Get accepter, slot and ballot from url, kind from header, and value from body. */
+	from, err := n.getVarString(req, varAccepter)
+	if err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	slot, err := n.getVarInt(req, varSlot)
+	if err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	/* Assert Role, and sender. */
+	if err := n.learnsFrom(from, slot); err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	b, err := n.getVarBallot(req, varBallot)
+	if err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	k, err := n.getHeaderKind(req)
+	if err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v, code, err := n.getBodyValue(w, req)
+	if err != nil {
+		n.respondError(w, code, err.Error())
+		return
+	}
+	/* Count acceptance towards quorum. */
+	if err := n.learn(from, slot, b, k, v); err != nil {
+		n.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+/* Return error unless node learns acceptances for slot from accepter.
+ * Learners do, and so does coordinator of fast rounds.
+ */
+func (n *Node) learnsFrom(from string, slot int) error {
+
+	if !n.isAt(Learner, slot) && !(fastPaxos && n.coordinator() == n.server.Addr) {
+		return util.ErrorFormat(errWrongNodeType, "learner", PostLearn)
+	} else if n.config(slot).Network[from] != Accepter {
+		return util.ErrorFormat(errNotAccepter, from)
+	}
+	return nil
+}
+
+/* Record that accepter accepted ballot b with value v of kind k for slot.
+ * Commit value as chosen once a phase-2 quorum of accepters accepted the same ballot,
+ * or a fast quorum accepted the same value in a fast round.
+ * Coordinator recovers slot if values collided in fast round.
+ */
+func (n *Node) learn(from string, slot int, b Ballot, k Kind, v []byte) error {
+
+	n.mu.Lock()
+	/* Value of compacted slot is already known from snapshot. */
+	if slot <= n.snapshotSlotLocked() {
+		n.mu.Unlock()
+		return nil
+	}
+	accepted, ok := n.tally[slot]
+	if !ok {
+		accepted = map[string]Slot{}
+		n.tally[slot] = accepted
+	}
+	/* Accepters only ever accept increasing ballots. */
+	if accepted[from].N.Less(b) {
+		accepted[from] = Slot{N: b, Value: v, Kind: k}
+	}
+	votes := map[string]bool{}
+	for a, s := range accepted {
+		if s.N == b && s.Kind == k && bytes.Equal(s.Value, v) {
+			votes[a] = true
+		}
+	}
+	n.mu.Unlock()
+
+	/* Fast rounds need a fast quorum of the same value. */
+	c := n.config(slot)
+	chosen, collided, popular := c.Quorums.Phase2(votes), false, []byte(nil)
+	if b == fastBallot {
+		chosen = c.isFastQuorum(votes)
+		if n.isAt(Proposer, slot) {
+			n.mu.Lock()
+			collided, popular = c.collided(accepted)
+			n.mu.Unlock()
+		}
+	}
+	s := n.peekSlot(slot)
+	/* No value can be chosen in fast round; coordinator recovers slot. */
+	if collided && !s.Chosen {
+		go func() {
+			if err := n.recover(slot, popular); err != nil {
+				log.Error(err)
+			}
+		}()
+	}
+	/* Not yet chosen, or already learned. */
+	if !chosen || s.Chosen {
+		return nil
+	}
+	/* Chosen value never changes; forget tally for slot. */
+	n.mu.Lock()
+	delete(n.tally, slot)
+	n.mu.Unlock()
+
+	return n.choose(slot, b, k, v)
+}
+
+/* /chosen
+ * Role - Learner
+ */
+
+func (n *Node) GetChosen(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	/* Most recent slot with a chosen value. */
+	n.respondChosen(w, req, n.lastChosen())
+}
+
+/* /chosen/{slot}
+ * Role - Learner
+ */
+
+func (n *Node) GetChosenSlot(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	slot, err := n.getVarInt(req, varSlot)
+	if err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	n.respondChosen(w, req, slot)
+}
+
+/* Respond with json-body for chosen value of slot.
+ * Respond NOT FOUND if learner has not learned a value for slot.
+ */
+func (n *Node) respondChosen(w http.ResponseWriter, req *http.Request, slot int) {
+
+	/* Assert Role. */
+	if !n.is(Learner) {
+		err := util.ErrorFormat(errWrongNodeType, "learner", req.URL)
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if snap := n.latestSnapshot(); snap != nil && slot <= snap.Slot {
+		err := util.ErrorFormat(errCompacted, slot, snap.Slot)
+		n.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s := n.peekSlot(slot)
+	if !s.Chosen {
+		err := util.ErrorFormat(errNotChosen, slot)
+		n.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	body := map[string]interface{}{
+		JsonKeySlot:     slot,
+		JsonKeyChosen:   s.Value,
+		JsonKeyProposal: s.N,
+	}
+	/* Write before 200 OK is set on write. */
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(&body); err != nil {
+		msg := fmt.Sprintf("unable to encode response to [%s]: \n%s", req.URL, err.Error())
+		n.respondError(w, http.StatusInternalServerError, msg)
+		return
+	}
+}
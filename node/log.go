@@ -0,0 +1,280 @@
+package paxos
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/marius-j-i/paxos/util"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	/* Errors. */
+	errSlotRange = errors.New("invalid slot range [%d, %d]")
+	errKind      = errors.New("invalid kind [%s] of value for [%s]")
+
+	/* First index in the replicated log. */
+	firstSlot = 1
+
+	/* Header on accepts, and acceptances to learners, naming kind of value carried. */
+	headerKind = "Paxos-Kind"
+)
+
+/* Kinds of values chosen for slots. */
+const (
+	KindValue  Kind = iota // opaque value proposed by a client
+	KindChange             // membership change, only proposed through /members
+)
+
+/* Indicator of what a value chosen for a slot is.
+ * Values are opaque bytes; only the kind tells a membership change apart.
+ */
+type Kind int
+
+/* String descriptions of kinds. */
+var kindNames = map[Kind]string{
+	KindValue:  "value",
+	KindChange: "change",
+}
+
+/* State of a single paxos instance in the replicated log.
+ */
+type Slot struct {
+	Prepare Ballot `json:"prepare"` // most recent prepare-phase promise
+	N       Ballot `json:"N"`       // ballot of currently accepted value
+	Value   []byte `json:"value"`   // currently accepted value
+	Kind    Kind   `json:"kind"`    // kind of currently accepted value
+	Chosen  bool   `json:"chosen"`  // true if value is known to be chosen by a quorum
+}
+
+/* Return a new, empty slot.
+ */
+func newSlot() *Slot {
+	return &Slot{
+		Prepare: Ballot{},
+		N:       Ballot{},
+		Value:   nil,
+		Kind:    KindValue,
+		Chosen:  false,
+	}
+}
+
+/* Return slot with argument index, creating it if not yet in log.
+ * Slots compacted into a snapshot are returned, but not added back to log.
+ * Caller holds n.mu; slot must not be used after it is released.
+ */
+func (n *Node) slotLocked(i int) *Slot {
+	s, ok := n.log[i]
+	if !ok {
+		s = newSlot()
+		if i <= n.snapshotSlotLocked() {
+			return s
+		}
+		n.log[i] = s
+		/* Slots covered by a lease start promised to it. */
+		if n.lease.valid() && i >= n.lease.Slot {
+			s.Prepare = n.lease.Ballot
+		}
+	}
+	return s
+}
+
+/* Commit value v of kind k with ballot b as chosen for slot.
+ * Apply value if it is a membership change; a proposer that chose a new
+ * change announces it to every member. Values are applied to application
+ * in slot order, as slots before them are chosen.
+ */
+func (n *Node) choose(slot int, b Ballot, k Kind, v []byte) error {
+	learned, err := n.record(slot, b, k, v)
+	if err != nil {
+		return err
+	}
+	if learned && n.is(Proposer) {
+		go n.announceChange(slot, v)
+	}
+	/* Value is chosen regardless of whether application accepts it. */
+	if err := n.apply(); err != nil {
+		log.Error(err)
+	}
+	return nil
+}
+
+/* Record value v of kind k with ballot b as chosen for slot, and learn it
+ * if it is a membership change.
+ * Return true if a change not already known was learned.
+ */
+func (n *Node) record(slot int, b Ballot, k Kind, v []byte) (bool, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	s := n.slotLocked(slot)
+	s.N, s.Value, s.Kind, s.Chosen = b, v, k, true
+	err := n.persistLocked(slot)
+	/* Change is learned as its slot is chosen, so proposals waiting on window see both. */
+	learned := n.learnChangeLocked(slot, k, v)
+	n.windowed.Broadcast()
+	return learned, err
+}
+
+/* Return copy of slot with argument index without adding it to log.
+ */
+func (n *Node) peekSlot(i int) Slot {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if s, ok := n.log[i]; ok {
+		return *s
+	}
+	return *newSlot()
+}
+
+/* Return promise describing slot with argument index, as accepted by node,
+ * without adding it to log.
+ */
+func (n *Node) peekPromise(i int) *Promise {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	s, ok := n.log[i]
+	if !ok {
+		s = newSlot()
+	}
+	return newPromise().setNode(n, s, i)
+}
+
+/* Return index of greatest slot with an accepted value, or
+ * return slot of latest snapshot if log is empty.
+ */
+func (n *Node) lastSlot() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.lastSlotLocked()
+}
+
+/* Same as lastSlot.
+ * Caller holds n.mu.
+ */
+func (n *Node) lastSlotLocked() int {
+	last := n.snapshotSlotLocked()
+	for i, s := range n.log {
+		if !s.N.IsZero() && i > last {
+			last = i
+		}
+	}
+	return last
+}
+
+/* Return index of greatest slot with a chosen value, or
+ * return slot of latest snapshot if no value after it is chosen.
+ */
+func (n *Node) lastChosen() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.lastChosenLocked()
+}
+
+/* Same as lastChosen.
+ * Caller holds n.mu.
+ */
+func (n *Node) lastChosenLocked() int {
+	last := n.snapshotSlotLocked()
+	for i, s := range n.log {
+		if s.Chosen && i > last {
+			last = i
+		}
+	}
+	return last
+}
+
+/* Return index of greatest slot such that it, and every slot before it, is chosen.
+ * Caller holds n.mu.
+ */
+func (n *Node) chosenThroughLocked() int {
+	i := n.snapshotSlotLocked()
+	for s, ok := n.log[i+1]; ok && s.Chosen; s, ok = n.log[i+1] {
+		i++
+	}
+	return i
+}
+
+/* Return index of the next free slot in the log.
+ */
+func (n *Node) nextSlot() int {
+	return n.lastSlot() + 1
+}
+
+/* Reserve and return index of next free slot not already reserved
+ * by an in-flight proposal on node.
+ */
+func (n *Node) reserveSlot() int {
+	next := n.nextSlot()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for n.inflight[next] {
+		next++
+	}
+	n.inflight[next] = true
+	return next
+}
+
+/* Reserve slot with argument index.
+ * Return false if slot is already reserved by an in-flight proposal on node.
+ */
+func (n *Node) reserve(i int) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.inflight[i] {
+		return false
+	}
+	n.inflight[i] = true
+	return true
+}
+
+/* Release slot reserved by reserveSlot or reserve.
+ */
+func (n *Node) releaseSlot(i int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	delete(n.inflight, i)
+	n.windowed.Broadcast()
+}
+
+/* Return sorted indices of slots in log within [from, to].
+ */
+func (n *Node) slots(from, to int) ([]int, error) {
+	if from < firstSlot || to < from {
+		return nil, util.ErrorFormat(errSlotRange, from, to)
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	indices := []int{}
+	for i := range n.log {
+		if from <= i && i <= to {
+			indices = append(indices, i)
+		}
+	}
+	sort.Ints(indices)
+	return indices, nil
+}
+
+/* Return kind described by string, where an empty string is a value, or
+ * return error if description is not a kind.
+ */
+func parseKind(name, url string) (Kind, error) {
+	if name == `` {
+		return KindValue, nil
+	}
+	for k, s := range kindNames {
+		if s == name {
+			return k, nil
+		}
+	}
+	return 0, util.ErrorFormat(errKind, name, url)
+}
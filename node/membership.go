@@ -0,0 +1,458 @@
+package paxos
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/marius-j-i/paxos/util"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	/* Errors. */
+	errNotChange    = errors.New("value [%s] is not a membership change")
+	errNotConfirmed = errors.New("membership change [%s] is not confirmed chosen for slot [%d]")
+	errNoMemberID   = errors.New("membership change adds [%s] without an id")
+	errMemberID     = errors.New("membership change gives [%s] id [%d], held by [%s]")
+	errIDChanged    = errors.New("membership change gives [%s] id [%d]; it has id [%d]")
+	errBadChange    = errors.New("membership change of [%s] to [%s] leaves an invalid configuration: %s")
+
+	/* Globals. */
+	alphaWindow = 4 // membership change chosen for slot i is effective from slot i+alphaWindow
+)
+
+/* Membership of network effective from a slot onwards.
+ */
+type Config struct {
+	Slot    int             `json:"slot"`    // first slot configuration is effective for
+	Role    Role            `json:"role"`    // role of node itself
+	Network map[string]Role `json:"network"` // address mapping to role of network member, without self
+	IDs     map[string]int  `json:"ids"`     // address mapping to unique id of network member, self included
+	Quorums QuorumSystem    `json:"quorums"` // decides which accepters form a quorum in either phase
+	Fast    int             `json:"fast"`    // number of accepters needed to choose a value in a fast round
+}
+
+/* Change of role for a network member, chosen as a value of kind
+ * KindChange in the log. A zero role removes member from network.
+ */
+type Change struct {
+	Addr string `json:"addr"` // address of member
+	Role Role   `json:"role"` // new role of member
+	ID   int    `json:"id"`   // unique id of member; given when member is added
+}
+
+/* Set number of slots after which a chosen membership change is effective.
+ */
+func SetAlphaWindow(alpha int) {
+	alphaWindow = alpha
+}
+
+/* Return configuration for node at address with role, effective from slot onwards.
+ * Assert quorums among accepters of network intersect.
+ */
+func newConfig(slot int, addr string, role Role, network map[string]Role, ids map[string]int) (*Config, error) {
+
+	accepters := []string{}
+	if role == Accepter {
+		accepters = append(accepters, addr)
+	}
+	for member, r := range network {
+		if r == Accepter {
+			accepters = append(accepters, member)
+		}
+	}
+	/* Assert every phase-1 quorum intersects every phase-2 quorum. */
+	quorums, err := newQuorumSystem(accepters)
+	if err != nil {
+		return nil, err
+	}
+	/* Assert any phase-1 quorum intersects any two fast quorums. */
+	fast, err := newFastQuorum(quorums, len(accepters))
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{
+		Slot:    slot,
+		Role:    role,
+		Network: network,
+		IDs:     ids,
+		Quorums: quorums,
+		Fast:    fast,
+	}
+	return c, nil
+}
+
+/* Return configuration after change, effective from slot onwards, or
+ * return error if change adds a member without an id, or with an id
+ * already held, or gives an existing member another id.
+ */
+func (c *Config) apply(addr string, ch *Change, slot int) (*Config, error) {
+
+	role, network, ids := c.Role, map[string]Role{}, map[string]int{}
+	for member, r := range c.Network {
+		network[member] = r
+	}
+	for member, id := range c.IDs {
+		ids[member] = id
+	}
+	/* Members keep the id they were added with. */
+	if id, ok := ids[ch.Addr]; ok && ch.ID != 0 && ch.ID != id {
+		return nil, util.ErrorFormat(errIDChanged, ch.Addr, ch.ID, id)
+	} else if !ok && ch.Role != 0 {
+		if ch.ID == 0 {
+			return nil, util.ErrorFormat(errNoMemberID, ch.Addr)
+		}
+		for member, id := range ids {
+			if id == ch.ID {
+				return nil, util.ErrorFormat(errMemberID, ch.Addr, ch.ID, member)
+			}
+		}
+		ids[ch.Addr] = ch.ID
+	}
+	if ch.Role == 0 {
+		delete(ids, ch.Addr)
+	}
+	if ch.Addr == addr {
+		role = ch.Role
+	} else if ch.Role == 0 {
+		delete(network, ch.Addr)
+	} else {
+		network[ch.Addr] = ch.Role
+	}
+	return newConfig(slot, addr, role, network, ids)
+}
+
+/* Return number of members with argument role in configuration, without self.
+ */
+func (c *Config) LenRoles(r Role) (members int) {
+	for _, role := range c.Network {
+		if role == r {
+			members++
+		}
+	}
+	return
+}
+
+/* Return true if accepters form a fast quorum.
+ */
+func (c *Config) isFastQuorum(accepters map[string]bool) bool {
+	return c.Fast > 0 && len(accepters) >= c.Fast
+}
+
+/* Return configuration effective for slot.
+ */
+func (n *Node) config(slot int) *Config {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.configLocked(slot)
+}
+
+/* Same as config.
+ * Caller holds n.mu.
+ */
+func (n *Node) configLocked(slot int) *Config {
+	c := n.configs[0]
+	for _, next := range n.configs[1:] {
+		if next.Slot > slot {
+			break
+		}
+		c = next
+	}
+	return c
+}
+
+/* Return change of role for member at address. Member keeps its id, or if
+ * added, is given an id above any id known to node, so ids are never reused.
+ */
+func (n *Node) newChange(addr string, r Role) *Change {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	ch := &Change{Addr: addr, Role: r}
+	if id, ok := n.configs[len(n.configs)-1].IDs[addr]; ok {
+		ch.ID = id
+		return ch
+	}
+	for _, c := range n.configs {
+		for _, id := range c.IDs {
+			if id > ch.ID {
+				ch.ID = id
+			}
+		}
+	}
+	ch.ID++
+	return ch
+}
+
+/* Return error if change can not be applied to the latest configuration
+ * known to node, since it would be chosen but then skipped by every member.
+ */
+func (n *Node) checkChange(ch *Change) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	c := n.configs[len(n.configs)-1]
+	if _, err := c.apply(n.server.Addr, ch, c.Slot); err != nil {
+		return util.ErrorFormat(errBadChange, ch.Addr, ch.roleName(), err)
+	}
+	return nil
+}
+
+/* Return name of role change sets member to; none if member is removed.
+ */
+func (ch *Change) roleName() string {
+	if ch.Role == 0 {
+		return "none"
+	}
+	return roleNames[ch.Role]
+}
+
+/* Return log value encoding membership change.
+ */
+func (ch *Change) encode() ([]byte, error) {
+	return json.Marshal(ch)
+}
+
+/* Return membership change decoded from log value of kind KindChange, or
+ * return error if value does not encode a membership change.
+ */
+func decodeChange(v []byte) (*Change, error) {
+
+	ch := &Change{}
+	if err := json.Unmarshal(v, ch); err != nil {
+		return nil, util.ErrorFormat(errNotChange, v)
+	}
+	return ch, nil
+}
+
+/* Record value v of kind k chosen for slot if it is a membership change,
+ * and rebuild configurations in slot order. Only the kind of a value makes
+ * it a change; values of clients are never decoded.
+ * Return true if change was not already known.
+ */
+func (n *Node) learnChange(slot int, k Kind, v []byte) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.learnChangeLocked(slot, k, v)
+}
+
+/* Same as learnChange.
+ * Caller holds n.mu.
+ */
+func (n *Node) learnChangeLocked(slot int, k Kind, v []byte) bool {
+
+	if k != KindChange {
+		return false
+	}
+	ch, err := decodeChange(v)
+	if err != nil {
+		log.Error(err)
+		return false
+	}
+	if _, ok := n.changes[slot]; ok {
+		return false
+	}
+	n.changes[slot] = ch
+	n.reconfigure()
+
+	/* Lease was granted by accepters of previous configuration; re-elect. */
+	if n.lease.valid() && n.lease.Leader == n.server.Addr {
+		n.lease = nil
+	}
+	log.Infof("[%s] learned membership change {%s : %d} effective from slot [%d]",
+		n.server.Addr, ch.Addr, ch.Role, slot+alphaWindow)
+	return true
+}
+
+/* Rebuild configurations from initial configuration by applying
+ * known membership changes in slot order. Invalid changes are skipped.
+ * Caller holds n.mu.
+ */
+func (n *Node) reconfigure() {
+
+	slots := make([]int, 0, len(n.changes))
+	for i := range n.changes {
+		slots = append(slots, i)
+	}
+	sort.Ints(slots)
+
+	configs := []*Config{n.configs[0]}
+	for _, i := range slots {
+		next, err := configs[len(configs)-1].apply(n.server.Addr, n.changes[i], i+alphaWindow)
+		if err != nil {
+			log.Errorf("skip membership change for slot [%d]: %s", i, err)
+			continue
+		}
+		configs = append(configs, next)
+	}
+	/* Node is given its id by change adding it. */
+	if id, ok := configs[len(configs)-1].IDs[n.server.Addr]; ok {
+		n.id = id
+	}
+	n.configs = configs
+}
+
+/* Learn membership change v announced as chosen for slot, once confirmed:
+ * node chose it itself, or a phase-2 quorum of accepters of slot accepted
+ * it with the same ballot. An announcement alone is not trusted.
+ * Return error if change is not confirmed chosen.
+ */
+func (n *Node) configure(slot int, v []byte) error {
+
+	/* Change compacted into snapshot is known from it. */
+	if n.compacted(slot) {
+		return nil
+	}
+	if s := n.peekSlot(slot); s.Chosen {
+		if s.Kind != KindChange || !bytes.Equal(s.Value, v) {
+			return util.ErrorFormat(errNotConfirmed, v, slot)
+		}
+		n.learnChange(slot, KindChange, v)
+		return nil
+	}
+
+	/* Go routine. */
+	peek := func(addr string, promises chan *Promise) {
+		p, err := n.transport.Peek(addr, slot)
+		if err != nil {
+			log.Debug(err)
+		}
+		promises <- p
+	}
+	/* Fan-out to accepters of slot; node counts itself if one. */
+	c := n.config(slot)
+	promises := make(chan *Promise, len(c.Network)+1)
+	asked := 0
+	for addr, role := range c.Network {
+		if role == Accepter {
+			go peek(addr, promises)
+			asked++
+		}
+	}
+	if c.Role == Accepter {
+		promises <- n.peekPromise(slot)
+		asked++
+	}
+
+	/* Fan-in until a quorum accepted change with the same ballot. */
+	votes := map[Ballot]map[string]bool{}
+	for ; asked > 0; asked-- {
+		p := <-promises
+		if p == nil || p.N.IsZero() || p.Kind != KindChange || !bytes.Equal(p.Value, v) {
+			continue
+		}
+		if _, ok := votes[p.N]; !ok {
+			votes[p.N] = map[string]bool{}
+		}
+		votes[p.N][p.From] = true
+		if !c.Quorums.Phase2(votes[p.N]) {
+			continue
+		}
+		/* Chosen; announced already, so not announced again. */
+		if _, err := n.record(slot, p.N, KindChange, v); err != nil {
+			return err
+		}
+		if err := n.apply(); err != nil {
+			log.Error(err)
+		}
+		return nil
+	}
+	return util.ErrorFormat(errNotConfirmed, v, slot)
+}
+
+/* Fan-out membership change chosen for slot to every member of network,
+ * and every member of configuration change is effective for.
+ */
+func (n *Node) announceChange(slot int, v []byte) {
+
+	/* Go routine. */
+	announce := func(addr string) {
+		if err := n.transport.Configure(addr, slot, v); err != nil {
+			log.Debug(err)
+		}
+	}
+	members := map[string]bool{}
+	for addr := range n.members() {
+		members[addr] = true
+	}
+	for addr := range n.config(slot + alphaWindow).Network {
+		members[addr] = true
+	}
+
+	for addr := range members {
+		go announce(addr)
+	}
+}
+
+/* /members
+ * Role - Proposer
+ */
+
+func (n *Node) PostMembers(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	/* Assert Role. */
+	if !n.is(Proposer) {
+		err := util.ErrorFormat(errWrongNodeType, "proposer", req.URL)
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	/* Get member and new role from url. */
+	addr, err := n.getVarString(req, varMember)
+	if err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	name, err := n.getVarString(req, varRole)
+	if err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	/* Change is refused before it is chosen, rather than skipped after. */
+	ch := n.newChange(addr, parseRole(name))
+	if err := n.checkChange(ch); err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v, err := ch.encode()
+	if err != nil {
+		n.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	/* Change is chosen as any other value, but of its own kind. */
+	n.propose(w, req, KindChange, v)
+}
+
+/* /configure
+ * Role - Any
+ */
+
+func (n *Node) PostConfigure(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	/* Get slot from url, and chosen membership change from body. */
+	slot, err := n.getVarInt(req, varSlot)
+	if err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v, code, err := n.getBodyValue(w, req)
+	if err != nil {
+		n.respondError(w, code, err.Error())
+		return
+	} else if _, err := decodeChange(v); err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	/* Announcements are only learned once confirmed. */
+	if err := n.configure(slot, v); err != nil {
+		n.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,215 @@
+package paxos
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/marius-j-i/paxos/util"
+)
+
+/* Transport delivering messages between members in-process, over channels.
+ * No sockets are bound, so any number of networks may run side by side.
+ */
+type MemoryTransport struct {
+	inboxes map[string]chan *delivery // address of served member mapping to its inbox
+	mu      sync.Mutex                // guards inboxes
+}
+
+/* Message delivered to a member, handled by the receiving node.
+ */
+type delivery struct {
+	handle func(n *Node) error // handles message on receiving node
+	done   chan error          // receives error from handle once handled
+}
+
+/* Return new in-process transport, shared by every member of a network.
+ */
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{
+		inboxes: map[string]chan *delivery{},
+	}
+}
+
+/* Deliver message to member at address, and return once it is handled.
+ * Return error if member is not served, as a refused connection would.
+ */
+func (t *MemoryTransport) deliver(addr string, handle func(n *Node) error) error {
+	d := &delivery{
+		handle: handle,
+		done:   make(chan error, 1),
+	}
+	/* Inbox is not closed while sending. */
+	t.mu.Lock()
+	inbox, ok := t.inboxes[addr]
+	if ok {
+		inbox <- d
+	}
+	t.mu.Unlock()
+
+	if !ok {
+		return util.ErrorFormat(errUnreachable, addr)
+	}
+	return <-d.done
+}
+
+func (t *MemoryTransport) Prepare(ctx context.Context, addr string, slot int, b Ballot) (*Promise, error) {
+	var p *Promise
+	err := t.deliver(addr, func(n *Node) (err error) {
+		if !n.isAt(Accepter, slot) {
+			return util.ErrorFormat(errWrongNodeType, "accepter", PostPrepare)
+		}
+		p, err = n.prepare(ctx, slot, b)
+		return err
+	})
+	return p, err
+}
+
+func (t *MemoryTransport) Accept(ctx context.Context, addr string, slot int, b Ballot, k Kind, v []byte) (*Promise, error) {
+	var p *Promise
+	err := t.deliver(addr, func(n *Node) error {
+		if !n.isAt(Accepter, slot) {
+			return util.ErrorFormat(errWrongNodeType, "accepter", PostAccept)
+		}
+		promise, accepted, err := n.accept(ctx, slot, b, k, v)
+		if accepted {
+			/* Notify learners of acceptance. */
+			go n.notifyLearners(slot, b, k, v)
+		}
+		p = promise
+		return err
+	})
+	return p, err
+}
+
+func (t *MemoryTransport) Accepted(addr, from string, slot int, b Ballot, k Kind, v []byte) error {
+	return t.deliver(addr, func(n *Node) error {
+		if err := n.learnsFrom(from, slot); err != nil {
+			return err
+		}
+		return n.learn(from, slot, b, k, v)
+	})
+}
+
+func (t *MemoryTransport) Lease(addr, proposer string, slot int, b Ballot) (*Grant, error) {
+	var g *Grant
+	err := t.deliver(addr, func(n *Node) (err error) {
+		if !n.isAt(Accepter, slot) {
+			return util.ErrorFormat(errWrongNodeType, "accepter", PostLease)
+		}
+		g, err = n.grantLease(proposer, slot, b)
+		return err
+	})
+	return g, err
+}
+
+func (t *MemoryTransport) Configure(addr string, slot int, v []byte) error {
+	return t.deliver(addr, func(n *Node) error {
+		if _, err := decodeChange(v); err != nil {
+			return err
+		}
+		return n.configure(slot, v)
+	})
+}
+
+func (t *MemoryTransport) Peek(addr string, slot int) (*Promise, error) {
+	var p *Promise
+	err := t.deliver(addr, func(n *Node) error {
+		p = n.peekPromise(slot)
+		return nil
+	})
+	return p, err
+}
+
+func (t *MemoryTransport) Forward(ctx context.Context, addr, from string, k Kind, v []byte) (int, *Proposal, error) {
+	var code int
+	var p *Proposal
+	var err error
+	if err := t.deliver(addr, func(n *Node) error {
+		if !n.is(Proposer) {
+			code, err = http.StatusBadRequest, util.ErrorFormat(errWrongNodeType, "proposer", PostPropose)
+			return nil
+		}
+		code, p, err = n.proposeValue(ctx, k, v, true)
+		return nil
+	}); err != nil {
+		return 0, nil, err
+	}
+	return code, p, err
+}
+
+func (t *MemoryTransport) Snapshot(addr string) (*Snapshot, error) {
+	var snap *Snapshot
+	err := t.deliver(addr, func(n *Node) error {
+		if snap = n.latestSnapshot(); snap == nil {
+			return util.ErrorFormat(errNoSnapshot, addr)
+		}
+		return nil
+	})
+	return snap, err
+}
+
+func (t *MemoryTransport) Ping(addr string) error {
+	return t.deliver(addr, func(n *Node) error {
+		return nil
+	})
+}
+
+/* Return client whose requests are served in-process by handlers of members.
+ */
+func (t *MemoryTransport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+/* Serve request in-process by handler of member at request host.
+ * Implements RoundTripper interface.
+ */
+func (t *MemoryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	/* Servers see a non-nil body. */
+	req = req.Clone(req.Context())
+	if req.Body == nil {
+		req.Body = http.NoBody
+	}
+	w := httptest.NewRecorder()
+	err := t.deliver(req.URL.Host, func(n *Node) error {
+		n.server.Handler.ServeHTTP(w, req)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w.Result(), nil
+}
+
+/* Handle messages delivered to node until Close is called for node.
+ */
+func (t *MemoryTransport) Serve(n *Node) error {
+	inbox := make(chan *delivery)
+
+	t.mu.Lock()
+	t.inboxes[n.server.Addr] = inbox
+	t.mu.Unlock()
+
+	/* Messages are handled concurrently, as by a server. */
+	for d := range inbox {
+		go func(d *delivery) {
+			d.done <- d.handle(n)
+		}(d)
+	}
+	return nil
+}
+
+/* Stop delivering messages to node.
+ */
+func (t *MemoryTransport) Close(n *Node) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if inbox, ok := t.inboxes[n.server.Addr]; ok {
+		delete(t.inboxes, n.server.Addr)
+		close(inbox)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package paxos
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/marius-j-i/paxos/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	/* Metrics. */
+	metricsNamespace = "paxos"
+	phasePrepare     = "prepare" // label of messages, and quorums, of phase 1
+	phaseAccept      = "accept"  // label of messages, and quorums, of phase 2
+	phaseLease       = "lease"   // label of messages, and quorums, of phase 1 for every slot from a slot onwards
+)
+
+/* Metrics of protocol activity of a node, served on /metrics in the
+ * Prometheus text format. Every node keeps a registry of its own, so nodes
+ * sharing a process each serve their own metrics.
+ */
+type metrics struct {
+	registry   *prometheus.Registry
+	sent       *prometheus.CounterVec   // proposer; prepares, accepts and leases sent, by phase
+	received   *prometheus.CounterVec   // accepter; prepares, accepts and leases received, by phase
+	rejected   *prometheus.CounterVec   // accepter; prepares, accepts and leases rejected, by phase
+	rejections *prometheus.CounterVec   // proposer; rejections of prepares, accepts and leases received, by phase
+	proposals  *prometheus.CounterVec   // proposer; proposals completed, by result
+	attempts   prometheus.Histogram     // proposer; attempts per proposal, up to maxProposals
+	retries    prometheus.Counter       // proposer; failed attempts re-tried
+	quorumWait *prometheus.HistogramVec // proposer; time gathering replies, by phase and whether quorum was reached
+	peerErrors *prometheus.CounterVec   // messages to peers that failed, by peer and message
+	persist    prometheus.Histogram     // time to make state durable in storage
+}
+
+/* Return metrics of node, registered with a registry of its own.
+ */
+func newMetrics(n *Node) *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		sent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "messages_sent_total",
+			Help:      "Prepare, accept and lease messages sent by proposer to accepters.",
+		}, []string{"phase"}),
+		received: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "messages_received_total",
+			Help:      "Prepare, accept and lease messages received by accepter.",
+		}, []string{"phase"}),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "messages_rejected_total",
+			Help:      "Prepare, accept and lease messages rejected by accepter.",
+		}, []string{"phase"}),
+		rejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "messages_rejections_observed_total",
+			Help:      "Rejections of prepare, accept and lease messages received by proposer from accepters.",
+		}, []string{"phase"}),
+		proposals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "proposals_total",
+			Help:      "Proposals completed by proposer, by HTTP status code of their result.",
+		}, []string{"code"}),
+		attempts: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "proposal_attempts",
+			Help:      "Attempts per proposal; proposals are rejected after paxos_proposal_max_attempts.",
+			Buckets:   prometheus.LinearBuckets(1, 1, maxProposals),
+		}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "proposal_retries_total",
+			Help:      "Failed proposal attempts re-tried by proposer.",
+		}),
+		quorumWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "quorum_wait_seconds",
+			Help:      "Time proposer waited for replies of accepters, until quorum was reached or every accepter replied.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{"phase", "quorum"}),
+		peerErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "peer_errors_total",
+			Help:      "Messages to peers that failed to reach them, or to get a reply.",
+		}, []string{"peer", "message"}),
+		persist: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "persist_seconds",
+			Help:      "Time to make state durable in storage.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 16),
+		}),
+	}
+
+	/* Ballots are read from node as metrics are gathered. */
+	ballot := func(name, help string, b func() Ballot) []prometheus.Collector {
+		return []prometheus.Collector{
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      name + "_round",
+				Help:      help + "; its round.",
+			}, func() float64 { return float64(b().Round) }),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      name + "_id",
+				Help:      help + "; id of proposer that issued it.",
+			}, func() float64 { return float64(b().ID) }),
+		}
+	}
+	maxAttempts := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "proposal_max_attempts",
+		Help:      "Attempts proposer makes for a proposal before rejecting it.",
+	}, func() float64 { return float64(maxProposals) })
+
+	m.registry.MustRegister(m.sent, m.received, m.rejected, m.rejections, m.proposals,
+		m.attempts, m.retries, m.quorumWait, m.peerErrors, m.persist, maxAttempts)
+	m.registry.MustRegister(ballot("ballot", "Highest ballot seen by node, which new proposals exceed", n.highestBallot)...)
+	m.registry.MustRegister(ballot("promised", "Highest ballot promised by node in any slot of its log", n.highestPromise)...)
+	m.registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	return m
+}
+
+/* Return handler serving metrics in the Prometheus text format.
+ */
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+/* Record a proposal completed with status code after attempts.
+ */
+func (m *metrics) proposed(code, attempts int) {
+	m.proposals.WithLabelValues(strconv.Itoa(code)).Inc()
+	m.attempts.Observe(float64(attempts))
+}
+
+/* Record time waited since start for replies of a phase.
+ */
+func (m *metrics) waited(phase string, start time.Time, quorum bool) {
+	m.quorumWait.WithLabelValues(phase, strconv.FormatBool(quorum)).Observe(time.Since(start).Seconds())
+}
+
+/* Return highest ballot seen by node.
+ */
+func (n *Node) highestBallot() Ballot {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.ballot
+}
+
+/* Return highest ballot promised in any slot of log, or by lease.
+ */
+func (n *Node) highestPromise() Ballot {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.highestPromiseLocked()
+}
+
+/* Same as highestPromise.
+ * Caller holds n.mu.
+ */
+func (n *Node) highestPromiseLocked() Ballot {
+	promised := Ballot{}
+	if n.lease != nil {
+		promised = n.lease.Ballot
+	}
+	for _, s := range n.log {
+		if s.Prepare.Greater(promised) {
+			promised = s.Prepare
+		}
+	}
+	return promised
+}
+
+/* Transport counting messages to peers that failed, by peer and message,
+ * and recording when peers last replied, and how fast.
+ * Snapshots are not counted, since members without one refuse requests.
+ */
+type measuredTransport struct {
+	Transport
+	m     *metrics
+	peers *peers
+}
+
+/* Record message to peer at address sent at start, failed unless err is nil.
+ */
+func (t *measuredTransport) record(addr, message string, start time.Time, err error) {
+	if err != nil {
+		t.m.peerErrors.WithLabelValues(addr, message).Inc()
+	}
+	t.peers.record(addr, time.Since(start), err)
+}
+
+func (t *measuredTransport) Prepare(ctx context.Context, addr string, slot int, b Ballot) (*Promise, error) {
+	start := time.Now()
+	p, err := t.Transport.Prepare(ctx, addr, slot, b)
+	t.record(addr, "prepare", start, err)
+	return p, err
+}
+
+func (t *measuredTransport) Accept(ctx context.Context, addr string, slot int, b Ballot, k Kind, v []byte) (*Promise, error) {
+	start := time.Now()
+	p, err := t.Transport.Accept(ctx, addr, slot, b, k, v)
+	t.record(addr, "accept", start, err)
+	return p, err
+}
+
+func (t *measuredTransport) Accepted(addr, from string, slot int, b Ballot, k Kind, v []byte) error {
+	start := time.Now()
+	err := t.Transport.Accepted(addr, from, slot, b, k, v)
+	t.record(addr, "accepted", start, err)
+	return err
+}
+
+func (t *measuredTransport) Lease(addr, proposer string, slot int, b Ballot) (*Grant, error) {
+	start := time.Now()
+	g, err := t.Transport.Lease(addr, proposer, slot, b)
+	t.record(addr, "lease", start, err)
+	return g, err
+}
+
+func (t *measuredTransport) Configure(addr string, slot int, v []byte) error {
+	start := time.Now()
+	err := t.Transport.Configure(addr, slot, v)
+	t.record(addr, "configure", start, err)
+	return err
+}
+
+func (t *measuredTransport) Peek(addr string, slot int) (*Promise, error) {
+	start := time.Now()
+	p, err := t.Transport.Peek(addr, slot)
+	t.record(addr, "peek", start, err)
+	return p, err
+}
+
+func (t *measuredTransport) Forward(ctx context.Context, addr, from string, k Kind, v []byte) (int, *Proposal, error) {
+	start := time.Now()
+	code, p, err := t.Transport.Forward(ctx, addr, from, k, v)
+	/* Errors of leader are replies; only an unreachable leader failed. */
+	var failed error
+	if code == 0 {
+		failed = err
+		if failed == nil {
+			failed = util.ErrorFormat(errUnreachable, addr)
+		}
+	}
+	t.record(addr, "forward", start, failed)
+	return code, p, err
+}
+
+func (t *measuredTransport) Ping(addr string) error {
+	start := time.Now()
+	err := t.Transport.Ping(addr)
+	t.record(addr, "ping", start, err)
+	return err
+}
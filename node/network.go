@@ -1,34 +1,63 @@
 package paxos
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/gorilla/mux"
-	"github.com/marius-j-i/paxos/util"
 )
 
 var (
+	/* Errors. */
+	errValueSize = errors.New("value in body of [%s] exceeds [%d] bytes")
+
 	/* Map-keys for mux regex parsing. */
-	regexNumeric         = "[0-9]+"
-	varValue, regexValue = "value", "[a-zA-Z0-9]+"
-	varN, regexN         = "N", regexNumeric
+	regexNumeric           = "[0-9]+"
+	varValue               = "value"
+	varBallot, regexBallot = "ballot", `[0-9]+\.[0-9]+`
+	varSlot, regexSlot     = "slot", regexNumeric
+	varFrom, varTo         = "from", "to"
+	varAccepter, regexAddr = "accepter", `[a-zA-Z0-9.\-]+:[0-9]+`
+	varProposer            = "proposer"
+	varMember              = "member"
+	varRole, regexRole     = "role", "proposer|accepter|learner|none"
 
 	/* API end-points. */
-	PostPropose  = fmt.Sprintf("/propose/{%s:%s}", varValue, regexValue)
-	PostPrepare  = fmt.Sprintf("/prepare/{%s:%s}", varN, regexN)
-	PostAccept   = fmt.Sprintf("/accept/{%s:%s}/{%s:%s}", varN, regexN, varValue, regexValue)
-	GetAccepted  = "/accepted"
-	GetAccepters = "/accepters"
-	GetLearners  = "/learners"
-	GetAlive     = "/alive"
+	PostPropose      = "/propose"
+	PostPrepare      = fmt.Sprintf("/prepare/{%s:%s}/{%s:%s}", varSlot, regexSlot, varBallot, regexBallot)
+	PostAccept       = fmt.Sprintf("/accept/{%s:%s}/{%s:%s}", varSlot, regexSlot, varBallot, regexBallot)
+	GetAccepted      = "/accepted"
+	GetAcceptedSlot  = fmt.Sprintf("/accepted/{%s:%s}", varSlot, regexSlot)
+	GetAcceptedRange = fmt.Sprintf("/accepted/{%s:%s}/{%s:%s}", varFrom, regexSlot, varTo, regexSlot)
+	PostLease        = fmt.Sprintf("/lease/{%s:%s}/{%s:%s}/{%s:%s}", varProposer, regexAddr, varSlot, regexSlot, varBallot, regexBallot)
+	PostFast         = fmt.Sprintf("/fast/{%s:%s}", varSlot, regexSlot)
+	PostLearn        = fmt.Sprintf("/learn/{%s:%s}/{%s:%s}/{%s:%s}", varAccepter, regexAddr, varSlot, regexSlot, varBallot, regexBallot)
+	PostMembers      = fmt.Sprintf("/members/{%s:%s}/{%s:%s}", varMember, regexAddr, varRole, regexRole)
+	PostConfigure    = fmt.Sprintf("/configure/{%s:%s}", varSlot, regexSlot)
+	GetChosen        = "/chosen"
+	GetChosenSlot    = fmt.Sprintf("/chosen/{%s:%s}", varSlot, regexSlot)
+	GetAccepters     = "/accepters"
+	GetLearners      = "/learners"
+	GetAlive         = "/alive"
+	GetSnapshot      = "/snapshot"
+	GetMetrics       = "/metrics"
+	GetStatus        = "/status"
+	GetDebugState    = "/debug/state"
 
 	/* HTTP. */
 	GET              = `GET`
 	POST             = `POST`
 	contentTypeBytes = "application/octet-stream"
+	maxValueSize     = int64(1 << 20) // upper limit on bytes in a value carried by a request body
 )
 
+/* Set upper limit on bytes in a value carried by a request body.
+ */
+func SetMaxValueSize(size int64) {
+	maxValueSize = size
+}
+
 /* Configure server paths to HTTP API.
  */
 func (n *Node) configureServer() error {
@@ -42,9 +71,22 @@ func (n *Node) configureServer() error {
 	n.routes[PostPrepare] = router.HandleFunc(PostPrepare, n.PostPrepare).Methods(POST)
 	n.routes[PostAccept] = router.HandleFunc(PostAccept, n.PostAccept).Methods(POST)
 	n.routes[GetAccepted] = router.HandleFunc(GetAccepted, n.GetAccepted).Methods(GET)
+	n.routes[GetAcceptedSlot] = router.HandleFunc(GetAcceptedSlot, n.GetAcceptedSlot).Methods(GET)
+	n.routes[GetAcceptedRange] = router.HandleFunc(GetAcceptedRange, n.GetAcceptedRange).Methods(GET)
+	n.routes[PostLease] = router.HandleFunc(PostLease, n.PostLease).Methods(POST)
+	n.routes[PostFast] = router.HandleFunc(PostFast, n.PostFast).Methods(POST)
+	n.routes[PostLearn] = router.HandleFunc(PostLearn, n.PostLearn).Methods(POST)
+	n.routes[PostMembers] = router.HandleFunc(PostMembers, n.PostMembers).Methods(POST)
+	n.routes[PostConfigure] = router.HandleFunc(PostConfigure, n.PostConfigure).Methods(POST)
+	n.routes[GetChosen] = router.HandleFunc(GetChosen, n.GetChosen).Methods(GET)
+	n.routes[GetChosenSlot] = router.HandleFunc(GetChosenSlot, n.GetChosenSlot).Methods(GET)
 	n.routes[GetAccepters] = router.HandleFunc(GetAccepters, n.GetAccepters).Methods(GET)
 	n.routes[GetLearners] = router.HandleFunc(GetLearners, n.GetLearners).Methods(GET)
 	n.routes[GetAlive] = router.HandleFunc(GetAlive, n.GetAlive).Methods(GET)
+	n.routes[GetSnapshot] = router.HandleFunc(GetSnapshot, n.GetSnapshot).Methods(GET)
+	n.routes[GetMetrics] = router.Handle(GetMetrics, n.metrics.handler()).Methods(GET)
+	n.routes[GetStatus] = router.HandleFunc(GetStatus, n.GetStatus).Methods(GET)
+	n.routes[GetDebugState] = router.HandleFunc(GetDebugState, n.GetDebugState).Methods(GET)
 
 	/* Set as handler for both API's. */
 	n.server.Handler = router
@@ -66,27 +108,22 @@ func (n *Node) respondError(w http.ResponseWriter, status int, extra ...string)
 func (n *Node) checkAlive(r Role) ([]string, error) {
 	a := []string{}
 
-	ping := func(url string, alive chan bool) {
-		if resp, err := http.Get(url); err != nil {
-			alive <- false
-		} else {
-			alive <- true
-			defer resp.Body.Close()
-		}
+	ping := func(addr string, alive chan bool) {
+		alive <- n.transport.Ping(addr) == nil
 	}
 
 	/* Fan-out. */
-	alive := make(chan bool, len(n.network))
-	for addr, role := range n.network {
+	network := n.members()
+	alive := make(chan bool, len(network))
+	for addr, role := range network {
 		if role != r {
 			continue
 		}
-		url := util.HttpUrl(addr, GetAlive)
-		go ping(url, alive)
+		go ping(addr, alive)
 	}
 
 	/* Fan-in. */
-	for addr, role := range n.network {
+	for addr, role := range network {
 		if role != r {
 			continue
 		} else if <-alive {
@@ -1,22 +1,15 @@
 package paxos
 
 import (
-	"bytes"
-	"context"
-	"errors"
-	"io"
 	"net/http"
-	"os"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/marius-j-i/paxos/util"
 )
 
 var (
-	/* Errors. */
-	errNoQuorum = errors.New("cannot achieve quorum with [%d] accepters")
-
 	/* Timeouts. */
 	SHUTDOWNTIMEOUT = 8 * time.Second
 )
@@ -31,42 +24,91 @@ const (
 /* Indicator of node role. */
 type Role int
 
+/* String descriptions of roles. */
+var roleNames = map[Role]string{
+	Proposer: "proposer",
+	Accepter: "accepter",
+	Learner:  "learner",
+}
+
 type Node struct {
-	role    Role                  // node role; proposer, accepter, or learner
-	quorum  int                   // number of accepters needed move from prepare phase
-	prepare int                   // most recent prepare-phase promise
-	N       int                   // number of currently accepted value
-	value   string                // currently accepted value
-	f       *os.File              // file to persist current state
-	routes  map[string]*mux.Route // url-path mapping to route instance
-	network map[string]Role       // address mapping to role of network member
-	server  *http.Server          // server...
-	body    io.Reader             // empty body to pass into post requests
+	id         int                     // unique id among network members; issued in ballots
+	configs    []*Config               // membership configurations, ordered by slot they are effective from
+	changes    map[int]*Change         // slot mapping to chosen membership change
+	log        map[int]*Slot           // slot index mapping to paxos instance in replicated log
+	ballot     Ballot                  // highest ballot seen in any slot; new proposals exceed it
+	tally      map[int]map[string]Slot // learner; slot mapping accepters to their accepted ballot and value
+	lease      *Lease                  // accepter; lease granted, proposer; lease held or learned
+	inflight   map[int]bool            // proposer; slots reserved by in-flight proposals
+	proposals  map[*InFlight]bool      // proposer; proposals in progress
+	mu         sync.Mutex              // guards log, ballot, tally, lease, inflight, proposals, configs, changes, snapshot, applied, synced and catchingUp
+	electing   sync.Mutex              // proposer; serializes elections, so proposals share a lease
+	windowed   *sync.Cond              // proposer; signalled on n.mu as slots are chosen or released
+	storage    Storage                 // keeps state durable across crashes
+	synced     time.Time               // time state was last made durable in storage
+	app        Application             // state machine chosen values are applied to, if any
+	snapshot   *Snapshot               // latest snapshot; slots up to its slot are compacted from log
+	applied    int                     // index of last slot applied, in order, to application
+	applying   sync.Mutex              // serializes applying values, and taking or installing snapshots
+	catchingUp bool                    // true while fetching snapshot from members
+	routes     map[string]*mux.Route   // url-path mapping to route instance
+	transport  Transport               // carries messages to network members
+	metrics    *metrics                // counters and histograms of protocol activity
+	peers      *peers                  // network members as last seen through transport
+	server     *http.Server            // server...
 }
 
-/* Return new node.
+/* Return new node, which communicates with network members over HTTP.
  */
 func NewNode(r Role, addr string, network map[string]Role) (*Node, error) {
+	return NewNodeWithTransport(r, addr, network, NewHttpTransport())
+}
+
+/* Return new node, which communicates with network members through transport.
+ * Node keeps state in storage given by SetPersistState and SetNodeDirectory.
+ */
+func NewNodeWithTransport(r Role, addr string, network map[string]Role, t Transport) (*Node, error) {
+	s, err := defaultStorage(r, addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewNodeWithStorage(r, addr, network, t, s)
+}
+
+/* Return new node, which communicates with network members through transport,
+ * and keeps state in storage. State saved in storage is restored.
+ */
+func NewNodeWithStorage(r Role, addr string, network map[string]Role, t Transport, s Storage) (*Node, error) {
 
 	n := &Node{
-		role:    r,
-		quorum:  0,
-		network: nil,
-		prepare: 0,
-		N:       0,
-		value:   ``,
-		f:       nil,
-		routes:  map[string]*mux.Route{},
-		server:  &http.Server{Addr: addr},
-		body:    bytes.NewReader([]byte{}),
+		id:        0,
+		configs:   nil,
+		changes:   map[int]*Change{},
+		log:       map[int]*Slot{},
+		ballot:    Ballot{},
+		tally:     map[int]map[string]Slot{},
+		lease:     nil,
+		inflight:  map[int]bool{},
+		proposals: map[*InFlight]bool{},
+		storage:   s,
+		app:       nil,
+		snapshot:  nil,
+		applied:   firstSlot - 1,
+		routes:    map[string]*mux.Route{},
+		transport: t,
+		server:    &http.Server{Addr: addr},
 	}
+	/* Failed messages to peers are counted, and replies timed. */
+	n.metrics, n.peers = newMetrics(n), newPeers()
+	n.windowed = sync.NewCond(&n.mu)
+	n.transport = &measuredTransport{Transport: t, m: n.metrics, peers: n.peers}
 
 	/* Route end-points to server. */
 	if err := n.configureServer(); err != nil {
 		return nil, err
-	} else if err := n.createNetwork(addr, network); err != nil {
+	} else if err := n.createNetwork(addr, r, network); err != nil {
 		return nil, err
-	} else if err := n.createNodeFile(addr); err != nil {
+	} else if err := n.restore(); err != nil {
 		return nil, err
 	}
 
@@ -74,26 +116,37 @@ func NewNode(r Role, addr string, network map[string]Role) (*Node, error) {
 }
 
 /* Copy and exclude self for network.
- * Count accepters and find quorum.
+ * Create initial configuration, with quorum system among accepters.
+ * Members of initial network are given ids from position of their
+ * address among all of them. Node not among network has neither role nor
+ * id until a membership change adds it.
  */
-func (n *Node) createNetwork(addr string, network map[string]Role) error {
+func (n *Node) createNetwork(addr string, r Role, network map[string]Role) error {
+
+	/* Every member agrees on order of addresses. */
+	addrs := make([]string, 0, len(network))
+	for member := range network {
+		addrs = append(addrs, member)
+	}
+	sort.Strings(addrs)
 
-	members := map[string]Role{}
-	accepters := 0
-	/* Create network without self and count required quorum. */
-	for member, role := range network {
+	/* Create network without self. */
+	members, ids := map[string]Role{}, map[string]int{}
+	for i, member := range addrs {
 		if member != addr {
-			members[member] = role
-		}
-		if role == Accepter {
-			accepters++
+			members[member] = network[member]
 		}
+		ids[member] = i + 1
 	}
-	/* Assert network can find quorum. */
-	if accepters%2 != 1 {
-		return util.ErrorFormat(errNoQuorum, accepters)
+	if _, ok := network[addr]; !ok {
+		r = 0
 	}
-	n.network, n.quorum = members, (accepters/2)+1
+	/* Membership from first slot, until changed through consensus. */
+	c, err := newConfig(firstSlot, addr, r, members, ids)
+	if err != nil {
+		return err
+	}
+	n.configs, n.id = []*Config{c}, ids[addr]
 	return nil
 }
 
@@ -102,7 +155,7 @@ func (n *Node) createNetwork(addr string, network map[string]Role) error {
  */
 func (n *Node) Serve(errchan chan error) {
 
-	if err := n.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if err := n.transport.Serve(n); err != nil {
 		errchan <- err
 	}
 
@@ -115,16 +168,12 @@ func (n *Node) Serve(errchan chan error) {
  */
 func (n *Node) Shutdown(errchan chan error) {
 
-	/* Create context interface for server to use when shutting down. */
-	ctx, cancel := context.WithTimeout(context.Background(), SHUTDOWNTIMEOUT)
-	defer cancel()
-
 	/* Shutdown gracefully within timeframe. */
-	if err := n.server.Shutdown(ctx); err != nil {
+	if err := n.transport.Close(n); err != nil {
 		errchan <- err
 	}
 
-	if err := n.cleanupNodeFile(); err != nil {
+	if err := n.closeStorage(); err != nil {
 		errchan <- err
 	}
 
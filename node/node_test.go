@@ -2,35 +2,50 @@ package paxos
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"hash/crc32"
+	"io"
 	"log"
 	"math/rand"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/marius-j-i/paxos/util"
 	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 var (
 	/* Errors. */
 	errWrongStatusCode = errors.New("wrong status code: expected %v, got %v")
+	errSpans           = errors.New("expected a proposal, and an accept-phase; got [%d] and [%d] spans")
 
 	/* Network parameters. */
-	proposers = 9
-	accepters = 19
-	learners  = 11
-	network   = &Network{} // global reference to instanciated network
-	msPerNode = 50         // ms per node in network to wait until stabilization
+	proposers   = 9
+	accepters   = 19
+	learners    = 11
+	network     = &Network{}             // global reference to instanciated network
+	client      = &http.Client{}         // client for requests to nodes in network
+	msPerNode   = 50                     // ms per node in network to wait until stabilization
+	learnWindow = 200 * time.Millisecond // time for learners to be notified of acceptance
 
 	/* Run-time. */
 	testDirOut = "test-nodes" // output directory for testing.
 	persist    = false
 	// persist    = true
-	emptyBody = bytes.NewReader([]byte{})
 )
 
 /* Setup network and return teardown method. */
@@ -48,7 +63,7 @@ func setup() func() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	network = N
+	network, client = N, N.Client()
 	/* Wait a certain number of ms for each node to be ready. */
 	ms := time.Duration(network.Len() * msPerNode)
 	time.Sleep(ms * time.Millisecond)
@@ -67,9 +82,68 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
+func TestBallot(t *testing.T) {
+
+	/* Ballots order by round, then by id of proposer. */
+	order := []struct {
+		b, other Ballot
+		less     bool
+	}{
+		{Ballot{Round: 1, ID: 1}, Ballot{Round: 2, ID: 1}, true},
+		{Ballot{Round: 1, ID: 9}, Ballot{Round: 2, ID: 1}, true},
+		{Ballot{Round: 2, ID: 1}, Ballot{Round: 2, ID: 2}, true},
+		{Ballot{Round: 2, ID: 2}, Ballot{Round: 2, ID: 1}, false},
+		{Ballot{Round: 3, ID: 1}, Ballot{Round: 2, ID: 9}, false},
+		{Ballot{Round: 2, ID: 2}, Ballot{Round: 2, ID: 2}, false},
+		{Ballot{}, Ballot{Round: 0, ID: 1}, true},
+	}
+	for _, o := range order {
+		assert.Equal(t, o.less, o.b.Less(o.other), "%s < %s", o.b, o.other)
+		assert.Equal(t, o.less, o.other.Greater(o.b), "%s > %s", o.other, o.b)
+		if o.b == o.other {
+			assert.False(t, o.b.Greater(o.other), "%s > %s", o.b, o.other)
+		}
+	}
+
+	/* Ballots round-trip through their string; malformed ones are refused. */
+	for _, b := range []Ballot{{}, {Round: 1, ID: 2}, {Round: 1234, ID: 56}} {
+		parsed, err := parseBallot(b.String())
+		assert.NoError(t, err)
+		assert.Equal(t, b, parsed)
+	}
+	for _, s := range []string{"", "1", "1-2", ".2", "1.", "a.2", "1.b", "1.2.3"} {
+		_, err := parseBallot(s)
+		assert.Error(t, err, "ballot [%s] parsed", s)
+	}
+
+	/* Proposers that saw the same ballots issue distinct ballots of the same round. */
+	seen := []Ballot{{Round: 4, ID: 1}, {Round: 2, ID: 3}}
+	b1, b2 := nextBallot(1, seen...), nextBallot(2, seen...)
+	assert.Equal(t, b1.Round, b2.Round)
+	assert.NotEqual(t, b1, b2)
+	assert.True(t, b1.Less(b2))
+	for _, b := range []Ballot{b1, b2} {
+		for _, s := range seen {
+			assert.True(t, b.Greater(s), "%s not greater than %s", b, s)
+		}
+	}
+
+	/* Every proposer of network issues ballots with an id of its own. */
+	P, _, _ := network.Members()
+	ids := map[int]string{}
+	for _, p := range P {
+		b := nextBallot(p.id)
+		if other, ok := ids[b.ID]; ok {
+			t.Errorf("proposers [%s] and [%s] issue ballots with id [%d]", other, p.server.Addr, b.ID)
+		}
+		ids[b.ID] = p.server.Addr
+	}
+}
+
 func TestProposer(t *testing.T) {
 
 	P, _, _ := network.Members()
+	first := network.LastSlot() + 1
 
 	/* Do proposals. */
 	proposals := 8
@@ -77,8 +151,8 @@ func TestProposer(t *testing.T) {
 		/* Random proposer. */
 		proposer := P[rand.Int()%len(P)]
 		/* Initiate proposal. */
-		url := util.HttpUrl(proposer.server.Addr, "propose", N+1)
-		if resp, err := http.Post(url, contentTypeBytes, emptyBody); err != nil {
+		url := util.HttpUrl(proposer.server.Addr, "propose")
+		if resp, err := client.Post(url, contentTypeBytes, strings.NewReader(strconv.Itoa(N+1))); err != nil {
 			failTest(t, err)
 		} else if resp.StatusCode != http.StatusCreated {
 			failTest(t, errWrongStatusCode,
@@ -87,13 +161,15 @@ func TestProposer(t *testing.T) {
 			resp.Body.Close()
 		}
 	}
-	/* Assert consensus. */
-	if _, v, err := network.Consensus(); err != nil {
-		failTest(t, err)
-	} else if value, err := strconv.Atoi(v); err != nil {
-		failTest(t, err)
-	} else if value != proposals {
-		assert.Equal(t, proposals, value)
+	/* Assert consensus on proposals appended in order. */
+	for N := 0; N < proposals; N++ {
+		if _, v, err := network.Consensus(first + N); err != nil {
+			failTest(t, err)
+		} else if value, err := strconv.Atoi(string(v)); err != nil {
+			failTest(t, err)
+		} else if value != N+1 {
+			assert.Equal(t, N+1, value)
+		}
 	}
 }
 
@@ -102,8 +178,8 @@ func TestProposerConcurrent(t *testing.T) {
 	/* Async method. */
 	propose := func(p *Node, v int, c chan error) {
 		/* Initiate proposal. */
-		url := util.HttpUrl(p.server.Addr, "propose", v+1)
-		if resp, err := http.Post(url, contentTypeBytes, emptyBody); err != nil {
+		url := util.HttpUrl(p.server.Addr, "propose")
+		if resp, err := client.Post(url, contentTypeBytes, strings.NewReader(strconv.Itoa(v+1))); err != nil {
 			c <- err
 		} else if resp.StatusCode != http.StatusCreated {
 			c <- util.ErrorFormat(errWrongStatusCode, resp.Status, http.StatusText(http.StatusCreated))
@@ -114,6 +190,7 @@ func TestProposerConcurrent(t *testing.T) {
 	}
 
 	P, _, _ := network.Members()
+	first := network.LastSlot() + 1
 
 	/* Do proposals. */
 	proposals := 8
@@ -130,14 +207,1211 @@ func TestProposerConcurrent(t *testing.T) {
 			t.Error(err)
 		}
 	}
-	/* Assert consensus. */
-	if _, v, err := network.Consensus(); err != nil {
+	/* Assert consensus on every slot, and that every proposal is in log. */
+	values := map[int]bool{}
+	for slot := first; slot <= network.LastSlot(); slot++ {
+		if _, v, err := network.Consensus(slot); err != nil {
+			failTest(t, err)
+		} else if value, err := strconv.Atoi(string(v)); err != nil {
+			failTest(t, err)
+		} else {
+			values[value] = true
+		}
+	}
+	for N := 0; N < proposals; N++ {
+		assert.True(t, values[N+1], "proposal [%d] not in log", N+1)
+	}
+}
+
+func TestAdoption(t *testing.T) {
+
+	N, err := NewNetwork(1, 3, 1)
+	if err != nil {
 		failTest(t, err)
-	} else if value, err := strconv.Atoi(v); err != nil {
+	}
+	defer N.Close()
+	time.Sleep(time.Duration(N.Len()*msPerNode) * time.Millisecond)
+	P, A, _ := N.Members()
+
+	/* A quorum of accepters accepted a value of an earlier proposal for slot. */
+	slot := N.LastSlot() + 1
+	for _, a := range A[:2] {
+		if _, _, err := a.accept(context.Background(), slot, Ballot{Round: 1, ID: 99}, KindValue, []byte("earlier")); err != nil {
+			failTest(t, err)
+		}
+	}
+	url := util.HttpUrl(P[0].server.Addr, "propose")
+	resp, err := N.Client().Post(url, contentTypeBytes, strings.NewReader("requested"))
+	if err != nil {
+		failTest(t, err)
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	p := &Proposal{}
+	if err := json.NewDecoder(resp.Body).Decode(p); err != nil {
+		failTest(t, err)
+	}
+
+	/* Slot is filled with adopted value, and requested value is chosen after it. */
+	assert.True(t, p.Adopted)
+	assert.Equal(t, []int{slot}, p.Filled)
+	assert.Equal(t, slot+1, p.Slot)
+	assert.Equal(t, []byte("requested"), p.Value)
+	if _, v, err := N.Consensus(slot); err != nil {
 		failTest(t, err)
-	} else if value != proposals {
-		assert.Equal(t, proposals, value)
+	} else {
+		assert.Equal(t, []byte("earlier"), v)
+	}
+	if _, v, err := N.Consensus(slot + 1); err != nil {
+		failTest(t, err)
+	} else {
+		assert.Equal(t, []byte("requested"), v)
+	}
+}
+
+func TestProposalRejected(t *testing.T) {
+
+	/* Attempts fail fast. */
+	defer SetProposalTimeout(time.Duration(proposalTimeoutLower)*proposalTimeoutUnit, time.Duration(proposalTimeoutUpper)*proposalTimeoutUnit)
+	SetProposalTimeout(10*time.Millisecond, 20*time.Millisecond)
+
+	N, err := NewNetwork(1, 3, 1)
+	if err != nil {
+		failTest(t, err)
+	}
+	defer N.Close()
+	time.Sleep(time.Duration(N.Len()*msPerNode) * time.Millisecond)
+	P, A, _ := N.Members()
+
+	/* A single accepter is left; no quorum of accepters is reachable. */
+	for _, a := range A[:2] {
+		if err := N.transport.Close(a); err != nil {
+			failTest(t, err)
+		}
+	}
+	chosen := P[0].lastChosen()
+	url := util.HttpUrl(P[0].server.Addr, "propose")
+	resp, err := N.Client().Post(url, contentTypeBytes, strings.NewReader("unchosen"))
+	if err != nil {
+		failTest(t, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		failTest(t, err)
+	}
+
+	/* Proposal fails after every attempt, instead of reporting a value chosen. */
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	rejected := util.ErrorFormat(errProposalRejected, []byte("unchosen"), maxProposals)
+	assert.Contains(t, string(body), rejected.Error())
+	assert.Equal(t, chosen, P[0].lastChosen())
+}
+
+func TestLeader(t *testing.T) {
+
+	/* Accepter grants a lease to one proposer at a time. */
+	addr, first, second := "localhost:9999", "localhost:9000", "localhost:9001"
+	members := map[string]Role{addr: Accepter, first: Proposer, second: Proposer}
+	a, err := NewNodeWithStorage(Accepter, addr, members, NewMemoryTransport(), NewMemoryStorage())
+	if err != nil {
+		failTest(t, err)
+	}
+	if g, err := a.grantLease(first, 1, Ballot{Round: 1, ID: 1}); err != nil {
+		failTest(t, err)
+	} else if assert.NotNil(t, g.Lease) {
+		assert.Equal(t, first, g.Lease.Leader)
+	}
+	/* Refused even for a higher ballot, while lease is valid. */
+	if g, err := a.grantLease(second, 1, Ballot{Round: 2, ID: 2}); err != nil {
+		failTest(t, err)
+	} else if assert.NotNil(t, g.Lease) {
+		assert.Equal(t, first, g.Lease.Leader)
+		assert.Equal(t, Ballot{Round: 1, ID: 1}, g.Lease.Ballot)
+	}
+
+	/* Leases expire soon. */
+	defer SetLeaseDuration(leaseDuration)
+	SetLeaseDuration(time.Second)
+
+	N, err := NewNetwork(2, 3, 1)
+	if err != nil {
+		failTest(t, err)
+	}
+	defer N.Close()
+	time.Sleep(time.Duration(N.Len()*msPerNode) * time.Millisecond)
+	P, A, _ := N.Members()
+
+	/* Return value of counter of node with name and phase. */
+	count := func(n *Node, name, phase string) float64 {
+		families, err := n.metrics.registry.Gather()
+		if err != nil {
+			failTest(t, err)
+		}
+		for _, f := range families {
+			if f.GetName() != metricsNamespace+"_"+name {
+				continue
+			}
+			for _, m := range f.GetMetric() {
+				for _, l := range m.GetLabel() {
+					if l.GetName() == "phase" && l.GetValue() == phase {
+						return m.GetCounter().GetValue()
+					}
+				}
+			}
+		}
+		return 0
+	}
+	/* Propose value to proposer, and return proposal chosen. */
+	propose := func(p *Node, v string) *Proposal {
+		url := util.HttpUrl(p.server.Addr, "propose")
+		resp, err := N.Client().Post(url, contentTypeBytes, strings.NewReader(v))
+		if err != nil {
+			failTest(t, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			failTest(t, errWrongStatusCode, resp.Status, http.StatusText(http.StatusCreated))
+		}
+		proposal := &Proposal{}
+		if err := json.NewDecoder(resp.Body).Decode(proposal); err != nil {
+			failTest(t, err)
+		}
+		return proposal
+	}
+
+	/* Leader is elected once, and skips prepare-phase while its lease holds. */
+	propose(P[0], "first")
+	propose(P[0], "second")
+	assert.True(t, P[0].isLeader())
+	assert.Equal(t, float64(len(A)), count(P[0], "messages_sent_total", phaseLease))
+	assert.Equal(t, float64(2*len(A)), count(P[0], "messages_sent_total", phaseAccept))
+	assert.Zero(t, count(P[0], "messages_sent_total", phasePrepare))
+	for _, a := range A {
+		assert.Zero(t, count(a, "messages_received_total", phasePrepare))
+	}
+
+	/* Another proposer learns of leader, and forwards proposals to it. */
+	p := propose(P[1], "forwarded")
+	assert.Equal(t, []byte("forwarded"), p.Value)
+	assert.Equal(t, P[0].server.Addr, P[1].leader())
+	assert.Equal(t, float64(3*len(A)), count(P[0], "messages_sent_total", phaseAccept))
+	assert.Zero(t, count(P[1], "messages_sent_total", phaseAccept))
+
+	/* Leader is gone; another proposer takes over once its lease expired. */
+	if err := N.transport.Close(P[0]); err != nil {
+		failTest(t, err)
+	}
+	time.Sleep(leaseDuration)
+	p = propose(P[1], "taken over")
+	assert.True(t, P[1].isLeader())
+	assert.Less(t, 0.0, count(P[1], "messages_sent_total", phaseAccept))
+	if _, v, err := N.Consensus(p.Slot); err != nil {
+		failTest(t, err)
+	} else {
+		assert.Equal(t, []byte("taken over"), v)
+	}
+}
+
+func TestLearner(t *testing.T) {
+
+	P, _, L := network.Members()
+	slot := network.LastSlot() + 1
+
+	/* Random proposer. */
+	proposer := P[rand.Int()%len(P)]
+	url := util.HttpUrl(proposer.server.Addr, "propose")
+	if resp, err := client.Post(url, contentTypeBytes, strings.NewReader("learned")); err != nil {
+		failTest(t, err)
+	} else if resp.StatusCode != http.StatusCreated {
+		failTest(t, errWrongStatusCode,
+			resp.Status, http.StatusText(http.StatusCreated))
+	} else {
+		resp.Body.Close()
+	}
+	/* Learners are notified asynchronously by accepters. */
+	time.Sleep(learnWindow)
+
+	/* Assert every learner learned the value chosen by consensus. */
+	_, v, err := network.Consensus(slot)
+	if err != nil {
+		failTest(t, err)
+	}
+	for _, l := range L {
+		var body map[string]interface{}
+
+		url := util.HttpUrl(l.server.Addr, "chosen", slot)
+		if resp, err := client.Get(url); err != nil {
+			failTest(t, err)
+		} else if resp.StatusCode != http.StatusOK {
+			failTest(t, errWrongStatusCode,
+				resp.Status, http.StatusText(http.StatusOK))
+		} else if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			failTest(t, err)
+		} else {
+			resp.Body.Close()
+			/* Values are base64 in json. */
+			assert.Equal(t, base64.StdEncoding.EncodeToString(v), body[JsonKeyChosen])
+		}
+	}
+}
+
+func TestBinaryValues(t *testing.T) {
+
+	P, _, _ := network.Members()
+	slot := network.LastSlot() + 1
+
+	/* Random proposer. */
+	proposer := P[rand.Int()%len(P)]
+	url := util.HttpUrl(proposer.server.Addr, "propose")
+
+	/* Opaque bytes are chosen as is. */
+	value := []byte("line one\nline two, with spaces & punctuation!\x00\xff")
+	if resp, err := client.Post(url, contentTypeBytes, bytes.NewReader(value)); err != nil {
+		failTest(t, err)
+	} else if resp.StatusCode != http.StatusCreated {
+		failTest(t, errWrongStatusCode,
+			resp.Status, http.StatusText(http.StatusCreated))
+	} else {
+		resp.Body.Close()
+	}
+	if _, v, err := network.Consensus(slot); err != nil {
+		failTest(t, err)
+	} else {
+		assert.Equal(t, value, v)
+	}
+
+	/* Values over size limit are refused. */
+	large := bytes.Repeat([]byte{'x'}, int(maxValueSize)+1)
+	if resp, err := client.Post(url, contentTypeBytes, bytes.NewReader(large)); err != nil {
+		failTest(t, err)
+	} else {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	}
+}
+
+func TestFastPaxos(t *testing.T) {
+
+	/* Fast rounds are refused while a leader holds a lease. */
+	SetLeaderElection(false)
+	SetFastPaxos(true)
+	defer SetLeaderElection(true)
+	defer SetFastPaxos(false)
+	time.Sleep(leaseDuration)
+
+	_, A, _ := network.Members()
+	slot := network.LastSlot() + 1
+
+	/* Async method. */
+	fast := func(a *Node, slot int, v string, c chan error) {
+		url := util.HttpUrl(a.server.Addr, "fast", slot)
+		if resp, err := client.Post(url, contentTypeBytes, strings.NewReader(v)); err != nil {
+			c <- err
+		} else if resp.StatusCode != http.StatusOK {
+			c <- util.ErrorFormat(errWrongStatusCode, resp.Status, http.StatusText(http.StatusOK))
+		} else {
+			resp.Body.Close()
+			c <- nil
+		}
+	}
+	/* Send values directly to every accepter, and return value chosen for slot.
+	 * Values are sent in turn to accepters. */
+	round := func(slot int, values ...string) string {
+		fan := make(chan error, len(A))
+		for i, a := range A {
+			go fast(a, slot, values[i%len(values)], fan)
+		}
+		for range A {
+			if err := <-fan; err != nil {
+				t.Error(err)
+			}
+		}
+		/* Wait for learners, or coordinator to recover a collision. */
+		deadline := time.Now().Add(time.Duration(maxProposals*proposalTimeoutUpper) * proposalTimeoutUnit)
+		for {
+			_, v, err := network.Consensus(slot)
+			if err == nil {
+				return string(v)
+			} else if time.Now().After(deadline) {
+				failTest(t, err)
+			}
+			time.Sleep(learnWindow)
+		}
+	}
+
+	/* Uncontended fast round is chosen in a single round-trip. */
+	assert.Equal(t, "fast", round(slot, "fast"))
+
+	/* Collided fast round is recovered with one of the values. */
+	v := round(slot+1, "red", "blue")
+	assert.Contains(t, []string{"red", "blue"}, v)
+}
+
+func TestMembership(t *testing.T) {
+
+	P, _, L := network.Members()
+	proposer := P[rand.Int()%len(P)]
+
+	/* Start accepter unknown to network. */
+	a, err := network.AddNode(Accepter)
+	if err != nil {
+		failTest(t, err)
+	}
+	time.Sleep(time.Duration(msPerNode) * time.Millisecond)
+
+	/* Propose value, or membership change, and return proposal chosen. */
+	propose := func(url, v string) *Proposal {
+		p := &Proposal{}
+		if resp, err := client.Post(url, contentTypeBytes, strings.NewReader(v)); err != nil {
+			failTest(t, err)
+		} else if resp.StatusCode != http.StatusCreated {
+			failTest(t, errWrongStatusCode,
+				resp.Status, http.StatusText(http.StatusCreated))
+		} else if err := json.NewDecoder(resp.Body).Decode(p); err != nil {
+			failTest(t, err)
+		} else {
+			resp.Body.Close()
+		}
+		return p
+	}
+	/* Return accepters proposer reports. */
+	accepters := func() []interface{} {
+		var body map[string]interface{}
+		url := util.HttpUrl(proposer.server.Addr, "accepters")
+		if resp, err := client.Get(url); err != nil {
+			failTest(t, err)
+		} else if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			failTest(t, err)
+		} else {
+			resp.Body.Close()
+		}
+		return body[JsonKeyAccepters].([]interface{})
+	}
+
+	/* Values of clients are opaque, however much they look like a change. */
+	forged := propose(util.HttpUrl(proposer.server.Addr, "propose"), `{"addr":"`+a.server.Addr+`","role":2}`)
+	assert.Equal(t, KindValue, forged.Kind)
+	_, member := proposer.config(forged.Slot + alphaWindow).Network[a.server.Addr]
+	assert.False(t, member, "value proposed by client changed membership")
+
+	/* Announcements of changes not chosen are refused, by learners and proposers alike. */
+	for _, n := range []*Node{L[0], proposer} {
+		for _, slot := range []int{forged.Slot, forged.Slot + 1000} {
+			url := util.HttpUrl(n.server.Addr, "configure", slot)
+			resp, err := client.Post(url, contentTypeBytes, strings.NewReader(`{"addr":"`+a.server.Addr+`","role":2}`))
+			if err != nil {
+				failTest(t, err)
+			}
+			resp.Body.Close()
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			assert.NotContains(t, n.config(slot+alphaWindow).Network, a.server.Addr)
+		}
+	}
+
+	/* Propose values until change chosen for slot is effective, after alpha
+	 * window, and return last slot proposed for. */
+	window := func(slot int) int {
+		last := slot
+		for N := 0; last < slot+alphaWindow; N++ {
+			last = propose(util.HttpUrl(proposer.server.Addr, "propose"), "window"+strconv.Itoa(N)).Slot
+		}
+		time.Sleep(learnWindow)
+		return last
+	}
+
+	/* Add accepter through consensus; not an accepter before alpha window. */
+	change := propose(util.HttpUrl(proposer.server.Addr, "members", a.server.Addr, "accepter"), ``)
+	assert.Equal(t, KindChange, change.Kind)
+	assert.False(t, a.isAt(Accepter, change.Slot+alphaWindow-1))
+	assert.NotContains(t, proposer.config(change.Slot+alphaWindow-1).Network, a.server.Addr)
+
+	/* Change is effective after alpha window. */
+	last := window(change.Slot)
+	assert.Contains(t, accepters(), a.server.Addr)
+	/* Accept may still be in flight once a quorum of others accepted. */
+	assert.Eventually(t, func() bool { return !a.peekSlot(last).N.IsZero() }, time.Second, 10*time.Millisecond,
+		"added accepter did not accept slot [%d]", last)
+	if _, _, err := network.Consensus(last); err != nil {
+		failTest(t, err)
+	}
+
+	/* Added accepter was given an id no other member holds. */
+	c := proposer.config(last)
+	assert.Equal(t, c.IDs[a.server.Addr], a.id)
+	for addr, id := range c.IDs {
+		assert.True(t, addr == a.server.Addr || id != a.id, "[%s] and [%s] hold id [%d]", addr, a.server.Addr, id)
+	}
+	/* Members are added with ids of their own, which they keep. */
+	for _, ch := range []*Change{
+		{Addr: "localhost:1", Role: Proposer},
+		{Addr: "localhost:1", Role: Proposer, ID: proposer.id},
+		{Addr: a.server.Addr, Role: Learner, ID: a.id + 1},
+	} {
+		_, err := c.apply(proposer.server.Addr, ch, last)
+		assert.Error(t, err, "change [%v] applied", ch)
+	}
+
+	/* Remove accepter again; still an accepter within alpha window. */
+	change = propose(util.HttpUrl(proposer.server.Addr, "members", a.server.Addr, "none"), ``)
+	assert.Contains(t, proposer.config(change.Slot+alphaWindow-1).Network, a.server.Addr)
+	window(change.Slot)
+	assert.NotContains(t, accepters(), a.server.Addr)
+}
+
+func TestWindow(t *testing.T) {
+
+	/* Proposer of a network of its own, with an empty log. */
+	N, err := NewNetwork(1, 3, 1)
+	if err != nil {
+		failTest(t, err)
+	}
+	defer N.Close()
+	P, _, _ := N.Members()
+	p := P[0]
+
+	/* Slots 1 and 2 are in flight, so proposal beyond window waits for them. */
+	slot := firstSlot + 1 + alphaWindow
+	for _, i := range []int{firstSlot, firstSlot + 1, slot} {
+		assert.True(t, p.reserve(i))
+	}
+	moved := make(chan int)
+	go func() { moved <- p.window(slot) }()
+
+	/* Slot 1 chosen is not enough; slot 2 is still below window. */
+	assert.NoError(t, p.choose(firstSlot, Ballot{Round: 1, ID: p.id}, KindValue, []byte("1")))
+	select {
+	case i := <-moved:
+		t.Fatalf("proposal for slot [%d] moved to [%d] before slot [%d] was chosen", slot, i, firstSlot+1)
+	case <-time.After(learnWindow):
+	}
+	/* Slot 2 released without a value; proposal moves down to fill it. */
+	p.releaseSlot(firstSlot + 1)
+	select {
+	case i := <-moved:
+		assert.Equal(t, firstSlot+1, i)
+	case <-time.After(time.Second):
+		t.Fatalf("proposal for slot [%d] never entered window", slot)
+	}
+}
+
+func TestMembershipRefused(t *testing.T) {
+
+	N, err := NewNetwork(1, 1, 1)
+	if err != nil {
+		failTest(t, err)
+	}
+	defer N.Close()
+	time.Sleep(time.Duration(N.Len()*msPerNode) * time.Millisecond)
+	P, A, _ := N.Members()
+
+	/* Return status code of change of member to role. */
+	change := func(member, role string) int {
+		resp, err := N.Client().Post(util.HttpUrl(P[0].server.Addr, "members", member, role), contentTypeBytes, nil)
+		if err != nil {
+			failTest(t, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	last := N.LastSlot()
+
+	/* Removing the last accepter leaves no quorum; refused change is never proposed. */
+	assert.Equal(t, http.StatusBadRequest, change(A[0].server.Addr, "none"))
+	assert.Equal(t, last, N.LastSlot())
+	assert.Equal(t, Accepter, P[0].config(P[0].nextSlot()+alphaWindow).Network[A[0].server.Addr])
+
+	/* Valid changes still are; wait for members to learn it. */
+	assert.Equal(t, http.StatusCreated, change("localhost:1", "learner"))
+	time.Sleep(learnWindow)
+	last = N.LastSlot()
+
+	/* Accepter added has no weight in quorum system. */
+	SetQuorumSystem(&Weighted{Weights: map[string]int{A[0].server.Addr: 1}, Q1: 1, Q2: 1})
+	defer SetQuorumSystem(nil)
+	assert.Equal(t, http.StatusBadRequest, change("localhost:2", "accepter"))
+	assert.Equal(t, last, N.LastSlot())
+}
+
+func TestQuorums(t *testing.T) {
+
+	/* Restore majority quorums for other tests. */
+	defer SetQuorums(0, 0)
+
+	members := map[string]Role{}
+	for i := 0; i < 6; i++ {
+		members["localhost:"+strconv.Itoa(i+1)] = Accepter
+	}
+	cases := []struct {
+		q1, q2 int  // configured quorum sizes
+		ok     bool // true if every phase-1 quorum intersects every phase-2 quorum
+	}{
+		{0, 0, true},  // majorities of even accepter count
+		{5, 2, true},  // write-heavy; small phase-2 quorum
+		{2, 5, true},  // read-heavy; small phase-1 quorum
+		{3, 3, false}, // disjoint quorums
+		{6, 0, true},  // zero is majority
+		{7, 1, false}, // more than there are accepters
+	}
+	for _, c := range cases {
+		SetQuorums(c.q1, c.q2)
+		n := &Node{}
+		err := n.createNetwork("localhost:0", Proposer, members)
+		assert.Equal(t, c.ok, err == nil, "quorums [%d, %d]: %v", c.q1, c.q2, err)
+	}
+}
+
+func TestQuorumSystems(t *testing.T) {
+
+	A := []string{"localhost:1", "localhost:2", "localhost:3", "localhost:4"}
+	set := func(accepters ...string) map[string]bool {
+		s := map[string]bool{}
+		for _, a := range accepters {
+			s[a] = true
+		}
+		return s
+	}
+
+	/* Reliable accepter outweighs the others. */
+	weighted := &Weighted{
+		Weights: map[string]int{A[0]: 3, A[1]: 1, A[2]: 1, A[3]: 1},
+		Q1:      4,
+		Q2:      3,
+	}
+	assert.NoError(t, weighted.Validate(A))
+	assert.True(t, weighted.Phase2(set(A[0])))
+	assert.False(t, weighted.Phase2(set(A[1], A[2])))
+	assert.True(t, weighted.Phase1(set(A[0], A[3])))
+	assert.False(t, weighted.Phase1(set(A[1], A[2], A[3])))
+
+	/* Disjoint weighted quorums. */
+	weighted.Q1 = 3
+	assert.Error(t, weighted.Validate(A))
+
+	/* Two rows of two accepters. */
+	grid := &Grid{Rows: [][]string{{A[0], A[1]}, {A[2], A[3]}}}
+	assert.NoError(t, grid.Validate(A))
+	assert.True(t, grid.Phase1(set(A[2], A[3])))
+	assert.False(t, grid.Phase1(set(A[0], A[2])))
+	assert.True(t, grid.Phase2(set(A[0], A[2])))
+	assert.False(t, grid.Phase2(set(A[0], A[1])))
+
+	/* Every accepter must be in exactly one row. */
+	grid.Rows = [][]string{{A[0], A[1]}, {A[2]}}
+	assert.Error(t, grid.Validate(A))
+	grid.Rows = [][]string{{A[0], A[1]}, {A[1], A[2], A[3]}}
+	assert.Error(t, grid.Validate(A))
+}
+
+func TestPersistence(t *testing.T) {
+
+	/* Restore state for this test only. */
+	SetRestorePersistentState(true)
+	defer SetRestorePersistentState(false)
+
+	path := filepath.Join(t.TempDir(), "accepter")
+	addr := "localhost:9999"
+	members := map[string]Role{addr: Accepter}
+	start := func() (*Node, error) {
+		s, err := NewFileStorage(path)
+		if err != nil {
+			return nil, err
+		}
+		return NewNodeWithStorage(Accepter, addr, members, NewMemoryTransport(), s)
+	}
+	a, err := start()
+	if err != nil {
+		failTest(t, err)
+	}
+
+	/* Promise, then accept a long value replaced by a shorter one. */
+	if _, err := a.prepare(context.Background(), 1, Ballot{Round: 1, ID: 1}); err != nil {
+		failTest(t, err)
+	} else if _, _, err := a.accept(context.Background(), 1, Ballot{Round: 1, ID: 1}, KindValue, bytes.Repeat([]byte("long"), 64)); err != nil {
+		failTest(t, err)
+	} else if _, _, err := a.accept(context.Background(), 1, Ballot{Round: 2, ID: 1}, KindValue, []byte("short")); err != nil {
+		failTest(t, err)
+	} else if _, err := a.prepare(context.Background(), 2, Ballot{Round: 3, ID: 1}); err != nil {
+		failTest(t, err)
+	}
+	/* State is written whole once, then every save appends a record. */
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		failTest(t, err)
+	}
+	records := bytes.Split(bytes.TrimSuffix(contents, []byte("\n")), []byte("\n"))
+	assert.Greater(t, len(records), 1, "saves were not appended: %s", contents)
+	for _, r := range records {
+		assert.True(t, json.Valid(r), "record is not valid json: %s", r)
+	}
+	_, err = os.Stat(path + tmpSuffix)
+	assert.True(t, errors.Is(err, os.ErrNotExist), "temporary state file was left behind")
+
+	/* Node restarted after a crash keeps promises and accepted values,
+	 * and an append left half-way is discarded. */
+	if err := os.WriteFile(path, append(contents, records[len(records)-1][:8]...), 0644); err != nil {
+		failTest(t, err)
+	}
+	r, err := start()
+	if err != nil {
+		failTest(t, err)
+	}
+	assert.Equal(t, Ballot{Round: 2, ID: 1}, r.peekSlot(1).N)
+	assert.Equal(t, []byte("short"), r.peekSlot(1).Value)
+	assert.Equal(t, Ballot{Round: 3, ID: 1}, r.peekSlot(2).Prepare)
+
+	/* Storage removed leaves no file behind, temporary or not. */
+	if err := os.WriteFile(path+tmpSuffix, contents, 0644); err != nil {
+		failTest(t, err)
+	}
+	assert.NoError(t, r.storage.Remove())
+	assert.NoFileExists(t, path)
+	assert.NoFileExists(t, path+tmpSuffix)
+
+	/* State that can not be restored is not silently discarded. */
+	if err := os.WriteFile(path, contents[:len(records[0])/2], 0644); err != nil {
+		failTest(t, err)
+	}
+	_, err = start()
+	assert.Error(t, err)
+}
+
+func TestStorage(t *testing.T) {
+
+	/* Restore state for this test only. */
+	SetRestorePersistentState(true)
+	defer SetRestorePersistentState(false)
+
+	dir := t.TempDir()
+	memory := NewMemoryStorage()
+	storages := map[string]func() (Storage, error){
+		"file": func() (Storage, error) {
+			return NewFileStorage(filepath.Join(dir, "file"))
+		},
+		"memory": func() (Storage, error) {
+			return memory, nil
+		},
+		"bolt": func() (Storage, error) {
+			return NewBoltStorage(filepath.Join(dir, "bolt"))
+		},
+	}
+	addr, proposer := "localhost:9999", "localhost:9000"
+	members := map[string]Role{addr: Accepter, proposer: Proposer}
+	start := func(t *testing.T, storage func() (Storage, error)) (*Node, Storage) {
+		s, err := storage()
+		if err != nil {
+			failTest(t, err)
+		}
+		n, err := NewNodeWithStorage(Accepter, addr, members, NewMemoryTransport(), s)
+		if err != nil {
+			failTest(t, err)
+		}
+		return n, s
+	}
+
+	for name, storage := range storages {
+		t.Run(name, func(t *testing.T) {
+
+			/* Grant lease, then accept values and promise a higher ballot. */
+			a, s := start(t, storage)
+			if _, err := a.grantLease(proposer, 1, Ballot{Round: 1, ID: 1}); err != nil {
+				failTest(t, err)
+			} else if _, _, err := a.accept(context.Background(), 1, Ballot{Round: 1, ID: 1}, KindValue, []byte("one")); err != nil {
+				failTest(t, err)
+			} else if _, _, err := a.accept(context.Background(), 2, Ballot{Round: 1, ID: 1}, KindValue, []byte("two")); err != nil {
+				failTest(t, err)
+			} else if err := a.choose(1, Ballot{Round: 1, ID: 1}, KindValue, []byte("one")); err != nil {
+				failTest(t, err)
+			} else if _, err := a.prepare(context.Background(), 3, Ballot{Round: 2, ID: 1}); err != nil {
+				failTest(t, err)
+			}
+			assert.NoError(t, s.Close())
+
+			/* Restarted node restores log and lease. */
+			r, s := start(t, storage)
+			assert.Equal(t, []byte("one"), r.peekSlot(1).Value)
+			assert.True(t, r.peekSlot(1).Chosen)
+			assert.Equal(t, []byte("two"), r.peekSlot(2).Value)
+			assert.Equal(t, Ballot{Round: 1, ID: 1}, r.peekSlot(2).N)
+			assert.Equal(t, Ballot{Round: 2, ID: 1}, r.peekSlot(3).Prepare)
+			if assert.NotNil(t, r.lease) {
+				assert.Equal(t, proposer, r.lease.Leader)
+			}
+
+			/* Snapshot compacts slots up to its slot; later slots are kept. */
+			snap := &Snapshot{Slot: 1, State: []byte("state"), Changes: map[int]*Change{}}
+			assert.NoError(t, s.SaveSnapshot(snap))
+			assert.NoError(t, s.Close())
+
+			c, s := start(t, storage)
+			assert.Equal(t, snap, c.latestSnapshot())
+			assert.True(t, c.peekSlot(1).N.IsZero())
+			assert.Equal(t, []byte("two"), c.peekSlot(2).Value)
+			assert.Equal(t, 1, c.lastChosen())
+			assert.NoError(t, s.Remove())
+
+			/* Removed state is not restored. */
+			e, s := start(t, storage)
+			assert.True(t, e.peekSlot(1).N.IsZero())
+			assert.Nil(t, e.lease)
+			assert.Nil(t, e.latestSnapshot())
+			assert.NoError(t, s.Remove())
+		})
+	}
+}
+
+func TestCorruption(t *testing.T) {
+
+	/* Restore state for this test only. */
+	SetRestorePersistentState(true)
+	defer SetRestorePersistentState(false)
+
+	addr := "localhost:9999"
+	members := map[string]Role{addr: Accepter}
+	start := func(s Storage, err error) (*Node, error) {
+		if err != nil {
+			return nil, err
+		}
+		return NewNodeWithStorage(Accepter, addr, members, NewMemoryTransport(), s)
+	}
+	/* Node saves a promise, and accepted value "short". */
+	save := func(n *Node, err error) {
+		if err != nil {
+			failTest(t, err)
+		} else if _, _, err := n.accept(context.Background(), 1, Ballot{Round: 1, ID: 1}, KindValue, []byte("short")); err != nil {
+			failTest(t, err)
+		} else if err := n.storage.Close(); err != nil {
+			failTest(t, err)
+		}
+	}
+	short, other := []byte(`"c2hvcnQ="`), []byte(`"c2hvcnU="`)
+
+	corruptions := map[string]struct {
+		corrupt func(r *record) []byte // returns record after corruption
+		reason  string                 // expected in error
+	}{
+		"bit flip": {func(r *record) []byte {
+			r.State = bytes.Replace(r.State, short, other, 1)
+			b, _ := json.Marshal(r)
+			return b
+		}, "checksum"},
+		"schema version": {func(r *record) []byte {
+			r.Version = schemaVersion + 1
+			b, _ := json.Marshal(r)
+			return b
+		}, "schema version"},
+		"wrong type": {func(r *record) []byte {
+			r.State = bytes.Replace(r.State, short, []byte(`7`), 1)
+			r.Checksum = crc32.Checksum(r.State, crcTable)
+			b, _ := json.Marshal(r)
+			return b
+		}, "corrupt"},
+		"unversioned": {func(r *record) []byte {
+			return r.State
+		}, "schema version [0]"},
+	}
+	for name, c := range corruptions {
+		t.Run("file/"+name, func(t *testing.T) {
+
+			path := filepath.Join(t.TempDir(), "accepter")
+			save(start(NewFileStorage(path)))
+
+			b, err := os.ReadFile(path)
+			if err != nil {
+				failTest(t, err)
+			}
+			r := &record{}
+			if err := json.Unmarshal(b, r); err != nil {
+				failTest(t, err)
+			} else if err := os.WriteFile(path, c.corrupt(r), 0644); err != nil {
+				failTest(t, err)
+			}
+			/* Node refuses to start, and names record and reason. */
+			_, err = start(NewFileStorage(path))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), path)
+				assert.Contains(t, err.Error(), c.reason)
+			}
+		})
+	}
+	t.Run("bolt/bit flip", func(t *testing.T) {
+
+		path := filepath.Join(t.TempDir(), "accepter")
+		save(start(NewBoltStorage(path)))
+
+		db, err := bolt.Open(path, 0644, nil)
+		if err != nil {
+			failTest(t, err)
+		}
+		err = db.Update(func(tx *bolt.Tx) error {
+			log := tx.Bucket(bucketLog)
+			return log.Put(slotKey(1), bytes.Replace(log.Get(slotKey(1)), short, other, 1))
+		})
+		if err != nil {
+			failTest(t, err)
+		} else if err := db.Close(); err != nil {
+			failTest(t, err)
+		}
+		_, err = start(NewBoltStorage(path))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), path+":log/1")
+			assert.Contains(t, err.Error(), "checksum")
+		}
+	})
+}
+
+/* Application recording values applied, in order.
+ */
+type recorder struct {
+	Values []string `json:"values"`
+}
+
+func (r *recorder) Apply(slot int, v []byte) error {
+	r.Values = append(r.Values, string(v))
+	return nil
+}
+
+func (r *recorder) Snapshot() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func (r *recorder) Restore(state []byte) error {
+	return json.Unmarshal(state, r)
+}
+
+func TestSnapshot(t *testing.T) {
+
+	/* Snapshot every few slots, in a network of its own. */
+	interval := 4
+	SetSnapshotInterval(interval)
+	defer SetSnapshotInterval(0)
+
+	N, err := NewNetwork(1, 3, 1)
+	if err != nil {
+		failTest(t, err)
+	}
+	defer N.Close()
+	time.Sleep(time.Duration(N.Len()*msPerNode) * time.Millisecond)
+	P, A, L := N.Members()
+	apps := map[*Node]*recorder{P[0]: {}, L[0]: {}}
+	for n, app := range apps {
+		if err := n.SetApplication(app); err != nil {
+			failTest(t, err)
+		}
+	}
+
+	/* Choose more values than fit between snapshots. */
+	values := []string{}
+	url := util.HttpUrl(P[0].server.Addr, "propose")
+	for i := 0; i < 3*interval; i++ {
+		values = append(values, strconv.Itoa(i+1))
+		if resp, err := N.Client().Post(url, contentTypeBytes, strings.NewReader(values[i])); err != nil {
+			failTest(t, err)
+		} else if resp.StatusCode != http.StatusCreated {
+			failTest(t, errWrongStatusCode,
+				resp.Status, http.StatusText(http.StatusCreated))
+		} else {
+			resp.Body.Close()
+		}
+	}
+	time.Sleep(learnWindow)
+
+	/* Applications saw every value once, in order, and logs are compacted. */
+	for n, app := range apps {
+		assert.Equal(t, values, app.Values)
+		snap := n.latestSnapshot()
+		if assert.NotNil(t, snap) {
+			assert.Equal(t, 3*interval, snap.Slot)
+			n.mu.Lock()
+			assert.LessOrEqual(t, len(n.log), interval)
+			n.mu.Unlock()
+		}
+		saved, err := n.storage.LoadSnapshot()
+		assert.NoError(t, err)
+		assert.Equal(t, snap, saved)
+	}
+	/* Compacted values are no longer served. */
+	if resp, err := N.Client().Get(util.HttpUrl(L[0].server.Addr, "chosen", 1)); err != nil {
+		failTest(t, err)
+	} else {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+
+	/* Accepters compact from snapshots of peers, and refuse compacted slots. */
+	for _, a := range A {
+		assert.NotNil(t, a.latestSnapshot())
+		p, err := a.prepare(context.Background(), 1, Ballot{Round: 99, ID: 1})
+		assert.NoError(t, err)
+		assert.True(t, p.compacted())
+		assert.True(t, a.peekSlot(1).Prepare.IsZero())
+	}
+
+	/* Lagging node catches up from snapshot, and restores application from it. */
+	l, err := N.AddNode(Learner)
+	if err != nil {
+		failTest(t, err)
+	}
+	time.Sleep(time.Duration(msPerNode) * time.Millisecond)
+	app := &recorder{}
+	if err := l.SetApplication(app); err != nil {
+		failTest(t, err)
+	}
+	l.catchUp()
+	assert.Equal(t, 3*interval, l.lastChosen())
+	assert.Equal(t, values, app.Values)
+}
+
+func TestMetrics(t *testing.T) {
+
+	/* Count messages of a single proposal, in a network of its own. */
+	N, err := NewNetwork(1, 3, 1)
+	if err != nil {
+		failTest(t, err)
+	}
+	defer N.Close()
+	time.Sleep(time.Duration(N.Len()*msPerNode) * time.Millisecond)
+	P, A, _ := N.Members()
+
+	url := util.HttpUrl(P[0].server.Addr, "propose")
+	if resp, err := N.Client().Post(url, contentTypeBytes, strings.NewReader("measured")); err != nil {
+		failTest(t, err)
+	} else if resp.StatusCode != http.StatusCreated {
+		failTest(t, errWrongStatusCode,
+			resp.Status, http.StatusText(http.StatusCreated))
+	} else {
+		resp.Body.Close()
+	}
+
+	/* Return value of series in metrics served by node. */
+	scrape := func(n *Node) func(series string) float64 {
+		resp, err := N.Client().Get(util.HttpUrl(n.server.Addr, "metrics"))
+		if err != nil {
+			failTest(t, err)
+		}
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			failTest(t, err)
+		}
+		return func(series string) float64 {
+			for _, line := range strings.Split(string(body), "\n") {
+				if v, ok := strings.CutPrefix(line, series+" "); ok {
+					f, err := strconv.ParseFloat(v, 64)
+					assert.NoError(t, err)
+					return f
+				}
+			}
+			t.Errorf("no series [%s] in metrics of [%s]", series, n.server.Addr)
+			return -1
+		}
+	}
+
+	/* Proposer chose value at first attempt, with a quorum of accepters. */
+	proposer := scrape(P[0])
+	assert.Equal(t, 1.0, proposer(`paxos_proposals_total{code="201"}`))
+	assert.Equal(t, 1.0, proposer(`paxos_proposal_attempts_count`))
+	assert.Equal(t, 1.0, proposer(`paxos_proposal_attempts_bucket{le="1"}`))
+	assert.Equal(t, float64(maxProposals), proposer(`paxos_proposal_max_attempts`))
+	assert.Equal(t, float64(len(A)), proposer(`paxos_messages_sent_total{phase="lease"}`))
+	assert.Equal(t, float64(len(A)), proposer(`paxos_messages_sent_total{phase="accept"}`))
+	assert.Equal(t, 1.0, proposer(`paxos_quorum_wait_seconds_count{phase="accept",quorum="true"}`))
+	assert.Equal(t, float64(P[0].highestBallot().Round), proposer(`paxos_ballot_round`))
+
+	/* Accepters received, persisted and promised proposal. */
+	for _, a := range A {
+		accepter := scrape(a)
+		assert.Equal(t, 1.0, accepter(`paxos_messages_received_total{phase="lease"}`))
+		assert.Equal(t, 1.0, accepter(`paxos_messages_received_total{phase="accept"}`))
+		assert.Less(t, 0.0, accepter(`paxos_persist_seconds_count`))
+		assert.Less(t, 0.0, accepter(`paxos_promised_round`))
+	}
+
+	/* A quorum of accepters promised a higher ballot rejects the next
+	 * accept; accepters count rejections as their own, and proposer as
+	 * ones observed. */
+	high := Ballot{Round: P[0].highestBallot().Round + 100, ID: 1}
+	for _, a := range A[:2] {
+		if _, err := a.prepare(context.Background(), 2, high); err != nil {
+			failTest(t, err)
+		}
+	}
+	if resp, err := N.Client().Post(url, contentTypeBytes, strings.NewReader("rejected")); err != nil {
+		failTest(t, err)
+	} else {
+		resp.Body.Close()
+	}
+	proposer, accepter := scrape(P[0]), scrape(A[0])
+	assert.Less(t, 0.0, accepter(`paxos_messages_rejected_total{phase="accept"}`))
+	assert.Less(t, 0.0, proposer(`paxos_messages_rejections_observed_total{phase="accept"}`))
+}
+
+func TestTracing(t *testing.T) {
+
+	/* Record spans of every node. */
+	recorder := tracetest.NewSpanRecorder()
+	SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer SetTracerProvider(noop.NewTracerProvider())
+
+	/* Trace context travels in headers of HTTP, and metadata of gRPC. */
+	networks := map[string]func(int, int, int) (*Network, error){
+		"http": NewHttpNetwork,
+		"grpc": NewGrpcNetwork,
+	}
+	for name, newNetwork := range networks {
+		t.Run(name, func(t *testing.T) {
+			N, err := newNetwork(1, 3, 1)
+			if err != nil {
+				failTest(t, err)
+			}
+			defer N.Close()
+			time.Sleep(time.Duration(N.Len()*msPerNode) * time.Millisecond)
+			P, A, _ := N.Members()
+
+			/* Propose in a trace started by caller. */
+			ctx, root := otel.Tracer("test").Start(context.Background(), "caller")
+			req, err := http.NewRequest(POST, util.HttpUrl(P[0].server.Addr, "propose"), strings.NewReader("traced"))
+			if err != nil {
+				failTest(t, err)
+			}
+			propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+			if resp, err := N.Client().Do(req); err != nil {
+				failTest(t, err)
+			} else if resp.StatusCode != http.StatusCreated {
+				failTest(t, errWrongStatusCode,
+					resp.Status, http.StatusText(http.StatusCreated))
+			} else {
+				resp.Body.Close()
+			}
+			root.End()
+
+			/* Return spans of trace named name. */
+			spans := func(name string) []sdktrace.ReadOnlySpan {
+				named := []sdktrace.ReadOnlySpan{}
+				for _, s := range recorder.Ended() {
+					if s.Name() == name && s.SpanContext().TraceID() == root.SpanContext().TraceID() {
+						named = append(named, s)
+					}
+				}
+				return named
+			}
+			/* Accepters beyond quorum may reply after proposal completed. */
+			assert.Eventually(t, func() bool {
+				return len(spans("paxos.accept.peer")) == len(A) && len(spans("paxos.accept.receive")) == len(A)
+			}, time.Second, 10*time.Millisecond)
+
+			/* Proposal is a child of caller, and accept-phase of proposal. */
+			propose, accept := spans("paxos.propose"), spans("paxos.accept")
+			if len(propose) != 1 || len(accept) != 1 {
+				failTest(t, errSpans, len(propose), len(accept))
+			}
+			assert.Equal(t, root.SpanContext().SpanID(), propose[0].Parent().SpanID())
+			assert.Equal(t, propose[0].SpanContext().SpanID(), accept[0].Parent().SpanID())
+
+			/* Every accepter was sent accept in a span of its own, which
+			 * accepter received in a child span. */
+			peers := map[string]bool{}
+			for _, s := range spans("paxos.accept.peer") {
+				assert.Equal(t, accept[0].SpanContext().SpanID(), s.Parent().SpanID())
+				for _, kv := range s.Attributes() {
+					switch kv.Key {
+					case "paxos.peer":
+						peers[kv.Value.AsString()] = true
+					case "paxos.outcome":
+						assert.Equal(t, outcomeAccepted, kv.Value.AsString())
+					}
+				}
+			}
+			assert.Len(t, peers, len(A))
+			for _, s := range spans("paxos.accept.receive") {
+				parent := false
+				for _, p := range spans("paxos.accept.peer") {
+					parent = parent || p.SpanContext().SpanID() == s.Parent().SpanID()
+				}
+				assert.True(t, parent, "span [%s] of accepter has no parent among proposer spans", s.Name())
+				assert.True(t, s.Parent().IsRemote())
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+
+	/* Status of a single proposal, in a network of its own. */
+	N, err := NewNetwork(1, 3, 1)
+	if err != nil {
+		failTest(t, err)
+	}
+	defer N.Close()
+	time.Sleep(time.Duration(N.Len()*msPerNode) * time.Millisecond)
+	P, A, _ := N.Members()
+
+	url := util.HttpUrl(P[0].server.Addr, "propose")
+	proposal := &Proposal{}
+	if resp, err := N.Client().Post(url, contentTypeBytes, strings.NewReader("status")); err != nil {
+		failTest(t, err)
+	} else if resp.StatusCode != http.StatusCreated {
+		failTest(t, errWrongStatusCode,
+			resp.Status, http.StatusText(http.StatusCreated))
+	} else {
+		defer resp.Body.Close()
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(proposal))
+	}
+
+	/* Decode state served by node at endpoint. */
+	get := func(n *Node, endpoint string, state interface{}) {
+		resp, err := N.Client().Get(util.HttpUrl(n.server.Addr, endpoint))
+		if err != nil {
+			failTest(t, err)
+		}
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(state))
+	}
+
+	/* Accepters beyond quorum may accept after proposal completed. */
+	assert.Eventually(t, func() bool {
+		for _, a := range A {
+			s := &Status{}
+			if get(a, "status", s); s.Slot != proposal.Slot {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	/* Accepters promised and accepted proposal, persisted it, and agree on values accepted. */
+	digests := map[string]bool{}
+	for _, a := range A {
+		s := &Status{}
+		get(a, "status", s)
+		assert.Equal(t, "accepter", s.Role)
+		assert.Equal(t, a.server.Addr, s.Addr)
+		assert.Equal(t, proposal.Ballot, s.Accepted)
+		assert.False(t, proposal.Ballot.Greater(s.Promised))
+		assert.Equal(t, proposal.Slot, s.Slot)
+		assert.False(t, s.Storage.Synced.IsZero())
+		assert.Len(t, s.Peers, N.Len()-1)
+		digests[s.Digest] = true
+	}
+	assert.Len(t, digests, 1)
+
+	/* Proposer leads with a majority quorum, saw every accepter, and has no proposal left in progress. */
+	state := &State{}
+	get(P[0], "debug/state", state)
+	assert.Equal(t, P[0].server.Addr, state.Leader)
+	assert.Equal(t, "counting", state.System)
+	quorums := &Counting{}
+	assert.NoError(t, json.Unmarshal(state.Quorums, quorums))
+	assert.Equal(t, Counting{Q1: 2, Q2: 2}, *quorums)
+	assert.Equal(t, len(A), state.Accepters)
+	for _, a := range A {
+		assert.False(t, state.Peers[a.server.Addr].LastSeen.IsZero(), "proposer never saw [%s]", a.server.Addr)
 	}
+	assert.Zero(t, state.InFlight)
+	assert.Empty(t, state.Proposals)
+	assert.Empty(t, state.Reserved)
 }
 
 func failTest(t *testing.T, err error, args ...interface{}) {
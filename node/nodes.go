@@ -1,9 +1,11 @@
 package paxos
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 
 	"github.com/marius-j-i/paxos/util"
 	log "github.com/sirupsen/logrus"
@@ -12,38 +14,106 @@ import (
 var (
 	/* Errors. */
 	errBrokenSafetyPropertySingleValue = errors.New("paxos safety property broken: `Only a single value is chosen, ...`")
-	errNoConsensus                     = errors.New("no consensus found for proposal, value := [%d, %s] with quorum := [%d/%d]")
+	errNoConsensus                     = errors.New("no consensus found for slot [%d] with ballot, value := [%s, %s] accepted by [%d] accepters")
 )
 
 /* Object for in-house handling of instansiated nodes.
  */
 type Network struct {
-	nodes   []*Node
-	errchan chan error
+	nodes     []*Node
+	errchan   chan error
+	transport Transport                   // carries messages between nodes
+	addr      func(i int) (string, error) // returns address of i'th node
 }
 
-/* Start a network of paxos nodes and return closer to shutdown nodes.
+/* Start a network of paxos nodes communicating in-process, and return
+ * closer to shutdown nodes. No sockets are bound.
  */
 func NewNetwork(proposers, accepters, learners int) (*Network, error) {
 
-	roles, addrs, network := createNetwork(proposers, accepters, learners)
+	addr := func(i int) (string, error) {
+		return networkAddr(i), nil
+	}
+	return newNetwork(proposers, accepters, learners, NewMemoryTransport(), addr)
+}
+
+/* Start a network of paxos nodes communicating over HTTP on free ports of
+ * localhost, and return closer to shutdown nodes.
+ */
+func NewHttpNetwork(proposers, accepters, learners int) (*Network, error) {
+
+	t := NewHttpTransport()
+	addr := func(i int) (string, error) {
+		return t.Listen("localhost")
+	}
+	return newNetwork(proposers, accepters, learners, t, addr)
+}
+
+/* Start a network of paxos nodes communicating over gRPC on free ports of
+ * localhost, and return closer to shutdown nodes. Nodes serve the HTTP API
+ * on the same ports.
+ */
+func NewGrpcNetwork(proposers, accepters, learners int) (*Network, error) {
+
+	t := NewGrpcTransport()
+	addr := func(i int) (string, error) {
+		return t.Listen("localhost")
+	}
+	return newNetwork(proposers, accepters, learners, t, addr)
+}
 
-	nodes, errchan, err := startNodes(roles, addrs, network)
+/* Start a network of paxos nodes communicating over HTTPS on free ports of
+ * localhost, authenticating each other with credentials, and return closer
+ * to shutdown nodes.
+ */
+func NewHttpNetworkTLS(proposers, accepters, learners int, c *Credentials) (*Network, error) {
+
+	t := NewHttpTransportTLS(c)
+	addr := func(i int) (string, error) {
+		return t.Listen("localhost")
+	}
+	return newNetwork(proposers, accepters, learners, t, addr)
+}
+
+/* Start a network of paxos nodes communicating over gRPC with TLS on free
+ * ports of localhost, authenticating each other with credentials, and return
+ * closer to shutdown nodes.
+ */
+func NewGrpcNetworkTLS(proposers, accepters, learners int, c *Credentials) (*Network, error) {
+
+	t := NewGrpcTransportTLS(c)
+	addr := func(i int) (string, error) {
+		return t.Listen("localhost")
+	}
+	return newNetwork(proposers, accepters, learners, t, addr)
+}
+
+/* Start a network of paxos nodes communicating through transport,
+ * where the i'th node is served on address returned by addr.
+ */
+func newNetwork(proposers, accepters, learners int, t Transport, addr func(i int) (string, error)) (*Network, error) {
+
+	roles, addrs, network, err := createNetwork(proposers, accepters, learners, addr)
+	if err != nil {
+		return nil, err
+	}
+	nodes, errchan, err := startNodes(roles, addrs, network, t)
 	if err != nil {
 		return nil, err
 	}
 
 	closer := &Network{
-		nodes:   nodes,
-		errchan: errchan,
+		nodes:     nodes,
+		errchan:   errchan,
+		transport: t,
+		addr:      addr,
 	}
 	return closer, nil
 }
 
 /* Return roles, addresses, and network map from arguments.
  */
-func createNetwork(proposers, accepters, learners int) ([]Role, []string, map[string]Role) {
-	host, startport := "localhost", 9000
+func createNetwork(proposers, accepters, learners int, addr func(i int) (string, error)) ([]Role, []string, map[string]Role, error) {
 
 	N := proposers + accepters + learners
 	roles := make([]Role, N)
@@ -52,8 +122,11 @@ func createNetwork(proposers, accepters, learners int) ([]Role, []string, map[st
 	/* Create arguments for NewNode. */
 	for i := 0; i < N; i++ {
 		/* Address. */
-		port := fmt.Sprint(startport + i)
-		addrs[i] = net.JoinHostPort(host, port)
+		a, err := addr(i)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		addrs[i] = a
 		/* Role. */
 		if i < proposers {
 			roles[i] = Proposer
@@ -65,17 +138,27 @@ func createNetwork(proposers, accepters, learners int) ([]Role, []string, map[st
 		/* Network. */
 		network[addrs[i]] = roles[i]
 	}
-	return roles, addrs, network
+	return roles, addrs, network, nil
+}
+
+/* Return address of i'th node in an in-process network.
+ * Addresses are only names; nothing is bound to them.
+ */
+func networkAddr(i int) string {
+	host, startport := "localhost", 9000
+
+	port := fmt.Sprint(startport + i)
+	return net.JoinHostPort(host, port)
 }
 
 /* Return created and started nodes, and channel they communicate with.
  */
-func startNodes(roles []Role, addrs []string, network map[string]Role) ([]*Node, chan error, error) {
+func startNodes(roles []Role, addrs []string, network map[string]Role, t Transport) ([]*Node, chan error, error) {
 
 	nodes := make([]*Node, len(network))
 	errchan := make(chan error, len(network))
 	for i := range nodes {
-		if n, err := NewNode(roles[i], addrs[i], network); err != nil {
+		if n, err := NewNodeWithTransport(roles[i], addrs[i], network, t); err != nil {
 			return nil, nil, err
 		} else {
 			nodes[i] = n
@@ -85,6 +168,36 @@ func startNodes(roles []Role, addrs []string, network map[string]Role) ([]*Node,
 	return nodes, errchan, nil
 }
 
+/* Create and start a node with argument role, which knows of network members.
+ * Node is not a member of network until a membership change adds it.
+ */
+func (N *Network) AddNode(r Role) (*Node, error) {
+
+	addr, err := N.addr(len(N.nodes))
+	if err != nil {
+		return nil, err
+	}
+	network := map[string]Role{}
+	for _, n := range N.nodes {
+		if r := n.currentRole(); r != 0 {
+			network[n.server.Addr] = r
+		}
+	}
+	n, err := NewNodeWithTransport(r, addr, network, N.transport)
+	if err != nil {
+		return nil, err
+	}
+	N.nodes = append(N.nodes, n)
+	go n.Serve(N.errchan)
+	return n, nil
+}
+
+/* Return client for requests to nodes in network, from outside network.
+ */
+func (N *Network) Client() *http.Client {
+	return N.transport.Client()
+}
+
 /* Return number of pnodes in network.
  */
 func (N *Network) Len() int {
@@ -96,7 +209,7 @@ func (N *Network) Len() int {
 func (N *Network) Members() (P []*Node, A []*Node, L []*Node) {
 
 	for _, n := range N.nodes {
-		switch n.role {
+		switch n.currentRole() {
 		case Proposer:
 			P = append(P, n)
 		case Accepter:
@@ -116,35 +229,69 @@ func (N *Network) Close() error {
 	return nil
 }
 
-/* Return proposal p and value v which network has consensus on.
- * Return (-1, "") if no consensus can be made.
+/* Return index of greatest slot any node in network has accepted a value for.
  */
-func (N *Network) Consensus() (int, string, error) {
-	p, v := -1, ""
+func (N *Network) LastSlot() int {
+	last := firstSlot - 1
+	for _, n := range N.nodes {
+		if s := n.lastSlot(); s > last {
+			last = s
+		}
+	}
+	return last
+}
 
-	/* Find greatest proposal p. */
+/* Return ballot b and value v which network has consensus on for slot.
+ * Return (Ballot{}, nil) if no consensus can be made.
+ */
+func (N *Network) Consensus(slot int) (Ballot, []byte, error) {
+	p, v := Ballot{}, []byte(nil)
+
+	/* Find greatest ballot p. */
 	for _, n := range N.nodes {
-		if n.N > p {
-			p, v = n.N, n.value
+		if s := n.peekSlot(slot); s.N.Greater(p) {
+			p, v = s.N, s.Value
 		}
 	}
+	/* No node accepted any value for slot. */
+	if p.IsZero() {
+		err := util.ErrorFormat(errNoConsensus,
+			slot, p, v, 0)
+		return Ballot{}, nil, err
+	}
 	/* Find values v with proposal p and assert they agree on value v.
-	 * Count quorum for proposal p and value v. */
-	quorum := 0
+	 * Gather accepters of proposal p and value v.
+	 * Values accepted in a fast round may differ; gather accepters of each. */
+	accepted := map[string]map[string]bool{}
 	for _, n := range N.nodes {
+		s := n.peekSlot(slot)
 		/* Updated accepters count for quorum. */
-		if n.role != Accepter || n.N != p {
+		if !n.isAt(Accepter, slot) || s.N != p {
 			continue
 		}
-		if /* Broken safety property. */ n.value != v {
-			return -1, "", errBrokenSafetyPropertySingleValue
+		if /* Broken safety property. */ !bytes.Equal(s.Value, v) && p != fastBallot {
+			return Ballot{}, nil, errBrokenSafetyPropertySingleValue
+		}
+		if _, ok := accepted[string(s.Value)]; !ok {
+			accepted[string(s.Value)] = map[string]bool{}
+		}
+		accepted[string(s.Value)][n.server.Addr] = true
+	}
+	c := N.nodes[0].config(slot)
+	chosen := c.Quorums.Phase2
+	if p == fastBallot {
+		chosen = c.isFastQuorum
+		/* At most one value gathers a fast quorum. */
+		for value, accepters := range accepted {
+			if chosen(accepters) {
+				v = []byte(value)
+			}
 		}
-		quorum++
 	}
-	if /* No quorum. */ quorum < N.nodes[0].quorum {
+	if /* No quorum. */ !chosen(accepted[string(v)]) {
 		err := util.ErrorFormat(errNoConsensus,
-			p, v, quorum, N.nodes[0].quorum)
-		return -1, "", err
+			slot, p, v, len(accepted[string(v)]))
+		return Ballot{}, nil, err
 	}
 	return p, v, nil
 }
@@ -155,8 +302,9 @@ func (N *Network) DestroyNetwork(nodes []*Node, errchan chan error) {
 	for _, n := range nodes {
 		go n.Shutdown(errchan)
 	}
-	/* Receive error until nil from each node. */
-	for i := 0; i < len(nodes); {
+	/* Receive error until nil from each node, both as it stops serving and
+	 * as its shutdown completes, so persistent state is cleaned up on return. */
+	for i := 0; i < 2*len(nodes); {
 		if err := <-errchan; err != nil {
 			log.Error(err)
 			continue
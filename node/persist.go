@@ -1,29 +1,26 @@
 package paxos
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"os"
 	"path"
+	"time"
 
 	"github.com/marius-j-i/paxos/util"
 )
 
 var (
 	/* Errors. */
-	errNoRestore  = errors.New("nodes set to not restore persistent state")
-	errTypeAssert = errors.New("unable to type assert keyword {%s : %v} from type [%T] into [%s]")
+	errRestore = errors.New("refusing to start [%s] with saved state it can not restore, which would forget its promises: %s")
 
 	/* Globals. */
-	persistState           = true  // on true; n.commit() will not persist state
-	restorePersistentState = true  // on true; NewNode() will not read any discovered state files
-	persistAfterShutdown   = false // on false; n.Shutdown() removes files from disk
+	persistState           = true  // on false; nodes without storage of their own keep state in memory only
+	restorePersistentState = true  // on false; NewNode() will not read any state saved in storage
+	persistAfterShutdown   = false // on false; n.Shutdown() removes state saved in storage
 	nodeDir                = "nodes"
 )
 
-/* Set n.commit() to not write state to disk.
+/* Set nodes created without storage of their own to not write state to disk.
  */
 func SetPersistState(trueOrFalse bool) {
 	persistState = trueOrFalse
@@ -47,123 +44,116 @@ func SetNodeDirectory(path string) {
 	nodeDir = path
 }
 
-/* Open value file for node, and make an initial commit.
- * If file already exists, restore state from contents if possible.
+/* Return storage for node with role and address that was created without
+ * storage of its own; a file named from both in nodeDir, or memory if nodes
+ * are set to not persist state.
  */
-func (n *Node) createNodeFile(addr string) error {
-
+func defaultStorage(r Role, addr string) (Storage, error) {
 	if !persistState {
-		return nil
+		return NewMemoryStorage(), nil
 	}
-	/* Directory for node. */
-	if err := os.MkdirAll(nodeDir, os.ModePerm); err != nil {
-		return err
-	}
-	/* Path to file. */
-	file := fmt.Sprintf("%s-%s", n.Role(), addr)
-	path := path.Join(nodeDir, file)
-	/* Restore from previous state, if any. */
-	if err := n.restore(path); err != nil {
-		/* else; Create node file. */
-		f, err := os.Create(path)
-		if err != nil {
-			return err
-		}
-		n.f = f
-		/* Commit initial state. */
-		if err := n.commit(n.N, n.value); err != nil {
-			return err
-		}
-	}
-	return nil
+	file := fmt.Sprintf("%s-%s", roleNames[r], addr)
+	return NewFileStorage(path.Join(nodeDir, file))
 }
 
-/* Persist node and update struct-members to new values.
+/* Update slot in log to new values and persist node.
  */
-func (n *Node) commit(N int, v string) error {
-	n.N, n.value = N, v
+func (n *Node) commit(slot int, b Ballot, v []byte) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
 
-	if !persistState {
-		return nil
+	s := n.slotLocked(slot)
+	s.N, s.Value = b, v
+	return n.persistLocked(slot)
+}
+
+/* Persist argument slots of log, and lease, to storage.
+ * Caller holds n.mu, so state persisted is the state decided upon.
+ * State is durable on return; callers reply to peers only afterwards.
+ */
+func (n *Node) persistLocked(slots ...int) error {
+
+	changed := make(map[int]Slot, len(slots))
+	for _, i := range slots {
+		if s, ok := n.log[i]; ok {
+			changed[i] = *s
+		}
 	}
-	/* State to persist. */
-	node := map[string]interface{}{
-		"role":    n.role,
-		"addr":    n.server.Addr,
-		"N":       n.N,
-		"prepare": n.prepare,
-		"value":   n.value,
-		"quorum":  n.quorum,
-		"network": n.network,
+	var lease *Lease
+	if n.lease != nil {
+		l := *n.lease
+		lease = &l
 	}
-	/* Set file to overwrite previous state. */
-	if _, err := n.f.Seek(0, io.SeekStart); err != nil {
+	start := time.Now()
+	if err := n.storage.Save(changed, lease); err != nil {
 		return err
 	}
-	/* Persist to disk. */
-	if err := json.NewEncoder(n.f).Encode(&node); err != nil {
-		return err
+	n.metrics.persist.Observe(time.Since(start).Seconds())
+	n.synced = time.Now()
+	/* Log grows until node installs a snapshot from its peers. */
+	if n.laggingLocked() && !n.catchingUp {
+		n.catchingUp = true
+		go n.catchUp()
 	}
 	return nil
 }
 
-/* Restore node state from file.
+/* Restore node state from storage, if any was saved; latest snapshot, and
+ * slots after it. Values chosen after snapshot are applied once an
+ * application is set, or as further values are chosen.
+ * Return error if saved state can not be restored, since promises made
+ * before a crash would be forgotten.
  */
-func (n *Node) restore(path string) error {
-	var node map[string]interface{}
+func (n *Node) restore() error {
 
 	if !restorePersistentState {
-		return errNoRestore
+		return nil
 	}
-	/* Open to read and write. */
-	f, err := os.OpenFile(path, os.O_RDWR, os.ModePerm)
+	snap, err := n.storage.LoadSnapshot()
 	if err != nil {
-		return err
+		return util.ErrorFormat(errRestore, n.server.Addr, err)
 	}
-	n.f = f
-	/* Restore from n.commit. */
-	if err := json.NewDecoder(n.f).Decode(&node); err != nil {
-		return err
+	log, lease, err := n.storage.Load()
+	if errors.Is(err, errNoState) && snap == nil {
+		return nil
+	} else if errors.Is(err, errNoState) {
+		log = map[int]*Slot{}
+	} else if err != nil {
+		return util.ErrorFormat(errRestore, n.server.Addr, err)
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.log, n.lease = log, lease
+	if snap != nil {
+		n.applied = snap.Slot
+		n.compactLocked(snap)
+		for i, ch := range snap.Changes {
+			n.changes[i] = ch
+		}
 	}
-	/* Type assert and set state. */
-	kwN, kwPrepare, kwValue := "N", "prepare", "value"
-	if N, ok := node[kwN].(float64); !ok {
-		return util.ErrorFormat(errTypeAssert,
-			kwN, node[kwN], node[kwN], "float64")
-	} else if prepare, ok := node[kwPrepare].(float64); !ok {
-		return util.ErrorFormat(errTypeAssert,
-			kwPrepare, node[kwPrepare], node[kwPrepare], "string")
-	} else if value, ok := node[kwValue].(string); !ok {
-		return util.ErrorFormat(errTypeAssert,
-			kwValue, node[kwValue], node[kwValue], "string")
-	} else {
-		/* Convert to correct types. */
-		n.N, n.prepare, n.value = int(N), int(prepare), value
+	/* Replay membership changes chosen in log. */
+	for i, s := range n.log {
+		if !s.Chosen || s.Kind != KindChange {
+			continue
+		}
+		if ch, err := decodeChange(s.Value); err == nil {
+			n.changes[i] = ch
+		}
 	}
+	n.reconfigure()
 	return nil
 }
 
-/* Remove persistent state as part of shutdown,
+/* Release storage as part of shutdown, and remove state saved,
  * unless n.SetPersistAfterShutdown(true) has been invoked.
  */
-func (n *Node) cleanupNodeFile() error {
+func (n *Node) closeStorage() error {
 
-	if !persistState {
-		return nil
-	}
-	path := n.f.Name()
-	if err := n.f.Close(); err != nil {
-		return err
-	}
 	/* To keep... */
 	if persistAfterShutdown {
-		goto done
+		return n.storage.Close()
 	}
 	/* ... or not to keep. */
-	if err := os.Remove(path); err != nil {
-		return err
-	}
-
-done:
-	return nil
+	return n.storage.Remove()
 }
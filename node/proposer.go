@@ -1,6 +1,7 @@
 package paxos
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,11 +10,14 @@ import (
 
 	"github.com/marius-j-i/paxos/util"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
 	/* Errors, */
-	errNoValue = errors.New("url [%s] has no value [%s]")
+	errNoValue          = errors.New("url [%s] has no value [%s]")
+	errProposalRejected = errors.New("proposal for value [%s] not chosen after [%d] attempts")
 
 	/* Timeout. */
 	proposalTimeoutUnit  = time.Millisecond
@@ -24,6 +28,25 @@ var (
 	maxProposals = 8
 )
 
+/* Set interval proposers wait within, at random, before re-trying a failed proposal.
+ */
+func SetProposalTimeout(lower, upper time.Duration) {
+	proposalTimeoutLower = int(lower / proposalTimeoutUnit)
+	proposalTimeoutUpper = int(upper / proposalTimeoutUnit)
+}
+
+/* Outcome of a proposal for a slot in log.
+ * Body of response to /propose.
+ */
+type Proposal struct {
+	Slot    int    `json:"slot"`    // log index value was chosen for
+	Ballot  Ballot `json:"ballot"`  // ballot value was chosen with
+	Value   []byte `json:"value"`   // chosen value
+	Kind    Kind   `json:"kind"`    // kind of chosen value
+	Adopted bool   `json:"adopted"` // true if slots were filled with values adopted from accepters before requested value was chosen
+	Filled  []int  `json:"filled"`  // slots filled with adopted values, in order, before slot of requested value
+}
+
 /* /propose
  * Role - Proposer
  */
@@ -32,251 +55,498 @@ func (n *Node) PostPropose(w http.ResponseWriter, req *http.Request) {
 	defer req.Body.Close()
 
 	/* Assert Role. */
-	if n.role != Proposer {
+	if !n.is(Proposer) {
 		err := util.ErrorFormat(errWrongNodeType, "proposer", req.URL)
 		n.respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	/* Get proposal value from url. */
-	v, err := n.getVarString(req, varValue)
+	/* Get proposal value from body. */
+	v, code, err := n.getBodyValue(w, req)
 	if err != nil {
-		n.respondError(w, http.StatusBadRequest, err.Error())
+		n.respondError(w, code, err.Error())
 		return
 	}
-	/* Propose a limited number of times. */
-	for try := 0; try < maxProposals; try++ {
+	n.propose(w, req, KindValue, v)
+}
 
-		if code, err := n.postPropose(v); err != nil {
-			n.respondError(w, code, err.Error())
-			return
-		} else if code == http.StatusCreated {
-			break
-		}
+/* Propose value v of kind k until chosen for a slot, and respond with proposal chosen.
+ */
+func (n *Node) propose(w http.ResponseWriter, req *http.Request, k Kind, v []byte) {
+
+	/* Proposals forwarded from another proposer are not forwarded again. */
+	forwarded := req.Header.Get(headerForwarded) != ``
+
+	code, proposal, err := n.proposeValue(requestContext(req), k, v, forwarded)
+	if err != nil {
+		n.respondError(w, code, err.Error())
+		return
 	}
 	/* Proposal complete. */
 	w.WriteHeader(http.StatusCreated)
+
+	/* Report to caller slot of value, and slots filled with adopted values before it. */
+	if err := json.NewEncoder(w).Encode(proposal); err != nil {
+		log.Error(err)
+	}
 }
 
-/* Proposer attempt a proposal.
- * Return HTTP status code CREATED and nil if successful, or
+/* Propose value v of kind k until chosen for a slot, in a span child of any in ctx.
+ * Proposal is forwarded to leader, unless already forwarded, if another
+ * proposer is known to be leader.
+ * Return CREATED and proposal chosen, or
  *
- * return non-CREATED code and nil if a re-try is possible, or
+ * return status code and error if value was not chosen.
+ */
+func (n *Node) proposeValue(ctx context.Context, k Kind, v []byte, forwarded bool) (int, *Proposal, error) {
+	var proposal, p *Proposal
+	var code, try int
+	var err error
+	filled := []int{}
+
+	ctx, span := startSpan(ctx, "paxos.propose",
+		attribute.String("paxos.node", n.server.Addr),
+		attribute.Bool("paxos.forwarded", forwarded),
+		attribute.String("paxos.kind", kindNames[k]),
+		attribute.Int("paxos.value_size", len(v)))
+
+	/* Proposal is listed on /debug/state until complete. */
+	f := n.startProposal(v, forwarded)
+	defer n.endProposal(f)
+
+	/* Propose a limited number of times, appending to next free slot.
+	 * Failed attempts re-try the same slot, so no slot is left without a value. */
+	slot := n.reserveSlot()
+	for try < maxProposals {
+
+		/* Forward to leader, if another proposer is known to be leader. */
+		if leader := n.leader(); leaderElection && !forwarded && leader != `` {
+			n.releaseSlot(slot)
+			if code, p, err := n.forward(ctx, leader, k, v); code != 0 {
+				span.SetAttributes(attribute.String("paxos.leader", leader), attribute.Int("http.status_code", code))
+				endSpan(span, err)
+				return code, p, err
+			}
+			slot = n.reserveSlot()
+		}
+		slot = n.window(slot)
+		n.attempting(f, slot, try+1)
+		code, p, err = n.postPropose(ctx, slot, k, v)
+		if err != nil {
+			n.releaseSlot(slot)
+			n.proposed(span, code, try+1, err)
+			return code, nil, err
+		} else if code != http.StatusCreated {
+			/* Slot was compacted by accepters, so another value was chosen for it;
+			 * propose for next slot. Not a failed proposal either. */
+			if n.compacted(slot) {
+				span.AddEvent("slot compacted", trace.WithAttributes(attribute.Int("paxos.slot", slot)))
+				n.releaseSlot(slot)
+				slot = n.reserveSlot()
+				continue
+			}
+			span.AddEvent("attempt failed", trace.WithAttributes(
+				attribute.Int("paxos.attempt", try+1),
+				attribute.Int("paxos.slot", slot),
+				attribute.Int("http.status_code", code)))
+			if try++; try < maxProposals {
+				n.metrics.retries.Inc()
+			}
+			continue
+		}
+		proposal = p
+		/* Slot was filled with an adopted value; propose for next slot.
+		 * Not a failed proposal, so do not count as a re-try. */
+		if proposal.Adopted {
+			log.Infof("slot [%d] chose adopted value [%s] over [%s]",
+				proposal.Slot, proposal.Value, v)
+			span.AddEvent("value adopted", trace.WithAttributes(attribute.Int("paxos.slot", proposal.Slot)))
+			filled = append(filled, proposal.Slot)
+			n.releaseSlot(slot)
+			slot = n.reserveSlot()
+			continue
+		}
+		break
+	}
+	n.releaseSlot(slot)
+	/* Report rejection if requested value was not chosen. */
+	if code != http.StatusCreated {
+		err = util.ErrorFormat(errProposalRejected, v, maxProposals)
+		n.proposed(span, code, try, err)
+		return code, nil, err
+	}
+	/* Report slots filled with adopted values on the way to slot of value. */
+	proposal.Adopted, proposal.Filled = len(filled) > 0, filled
+	span.SetAttributes(attribute.Int("paxos.slot", proposal.Slot))
+	n.proposed(span, code, try+1, nil)
+	return code, proposal, nil
+}
+
+/* Return slot to propose for, once membership of every slot up to
+ * slot-alphaWindow is learned; configuration of slot depends on changes
+ * chosen for them. Proposal moves down to the lowest of those slots
+ * neither chosen nor reserved, filling it, or else waits for proposals
+ * in flight to fill them. Argument slot is reserved, as is slot returned.
+ */
+func (n *Node) window(slot int) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for chosen := n.chosenThroughLocked(); slot-alphaWindow > chosen; chosen = n.chosenThroughLocked() {
+		hole := chosen + 1
+		for ; hole <= slot-alphaWindow; hole++ {
+			if s, ok := n.log[hole]; !n.inflight[hole] && !(ok && s.Chosen) {
+				break
+			}
+		}
+		if hole > slot-alphaWindow {
+			n.windowed.Wait()
+			continue
+		}
+		delete(n.inflight, slot)
+		n.inflight[hole] = true
+		slot = hole
+		n.windowed.Broadcast()
+	}
+	return slot
+}
+
+/* Record proposal completed with status code after attempts, and end its
+ * span, with error err if non-nil.
+ */
+func (n *Node) proposed(span trace.Span, code, attempts int, err error) {
+	n.metrics.proposed(code, attempts)
+	span.SetAttributes(attribute.Int("http.status_code", code), attribute.Int("paxos.attempts", attempts))
+	endSpan(span, err)
+}
+
+/* Proposer attempt a proposal of value v of kind k for argument slot in log.
+ * Return HTTP status code CREATED and chosen proposal if successful, or
+ *
+ * return CONFLICT or SERVICE UNAVAILABLE and nil if a re-try is possible, or
  *
  * return respond code and error on terminating request error.
  */
-func (n *Node) postPropose(v string) (int, error) {
-	var code, N, NPrime int
-	var vPrime string
+func (n *Node) postPropose(ctx context.Context, slot int, k Kind, v []byte) (int, *Proposal, error) {
+	var code int
+	var b, bPrime Ballot
+	var kPrime Kind
+	var vPrime []byte
 	var quorum bool
+	var proposal *Proposal
 
-	/* New proposal. */
-	N = n.N + 1
-	n.prepare = N
-
-	/* Prepare-phase. */
-	quorum, NPrime, vPrime = n.Prepare(N, v)
-	if !quorum && NPrime >= N {
-		/* v' is either nodes' value or new accepted value.
-		 * Update self for consensus, then
-		 * propose with new proposal N and same request-value v. */
-		if err := n.commit(NPrime, vPrime); err != nil {
-			code = http.StatusInternalServerError
-			return code, err
+	if leaderElection {
+		/* Leader skips prepare-phase; lease promised its ballot for slot. */
+		var err error
+		if code, b, err = n.lead(ctx, slot); err != nil || code != http.StatusCreated {
+			return code, nil, err
+		}
+		/* Slot was completed with an adopted value during election. */
+		if filled := n.peekSlot(slot); !filled.N.IsZero() {
+			proposal = &Proposal{
+				Slot:    slot,
+				Ballot:  filled.N,
+				Value:   filled.Value,
+				Kind:    filled.Kind,
+				Adopted: true,
+			}
+			return http.StatusCreated, proposal, nil
+		}
+		kPrime, vPrime = k, v
+
+	} else {
+		/* New proposal, greater than any ballot seen. */
+		b = n.newBallot(slot)
+
+		/* Prepare-phase.
+		 * On quorum, b' is ballot of adopted value v' of kind k', or zero if v' = v. */
+		quorum, bPrime, kPrime, vPrime = n.Prepare(ctx, slot, b, k, v)
+		if !quorum {
+			/* Accepters promised to b' >= b for slot, or were unreachable.
+			 * Propose with new ballot b > b' and same request-value v. */
+			code = n.retry(slot, b, bPrime)
+			goto done
 		}
-		/* Random timeout for proposer to complete. */
-		n.timeout(proposalTimeoutLower, proposalTimeoutUpper, proposalTimeoutUnit)
-		/* Re-try proposal. */
-		code = http.StatusContinue
-		goto done
 	}
 
-	/* Accept-phase. */
-	n.Accept(N, v)
+	/* Accept-phase.
+	 * v' is either request-value v, or value adopted from accepters. */
+	if accepted, bReject := n.Accept(ctx, slot, b, kPrime, vPrime); !accepted {
+		/* Accepters promised to b' > b for slot after prepare-phase,
+		 * or were unreachable. Re-try from prepare-phase. */
+		code = n.retry(slot, b, bReject)
+		goto done
+	}
 
-	/* Commit proposal.
-	 *
-	 * if there is an NPrime >= N;
-	 * then a new proposal is in motion,
-	 * but this proposal is completed.
-	 * Quorum of acceptors may or may not be updated. */
-	if err := n.commit(N, v); err != nil {
+	/* Commit proposal chosen by quorum of accepters. */
+	if err := n.choose(slot, b, kPrime, vPrime); err != nil {
 		code = http.StatusInternalServerError
-		return code, err
+		return code, nil, err
 	}
 	code = http.StatusCreated
+	proposal = &Proposal{
+		Slot:    slot,
+		Ballot:  b,
+		Value:   vPrime,
+		Kind:    kPrime,
+		Adopted: !bPrime.IsZero(),
+	}
 
 done:
-	return code, nil
+	return code, proposal, nil
 }
 
-/* Proposer attempts to achieve quorum of promises from accepters.
- * Return argument (true, N, v) if quorum reached, or
+/* Prepare proposer to re-try proposal for slot after ballot b was rejected
+ * in favour of b', or accepters were unreachable.
+ * Return status code describing failed attempt.
+ */
+func (n *Node) retry(slot int, b, bPrime Ballot) int {
+	code := http.StatusServiceUnavailable
+	/* Leader that fails a proposal steps down and is re-elected. */
+	if n.isLeader() {
+		n.dropLease()
+	}
+	/* Rejected by a higher ballot, as opposed to unreachable accepters. */
+	if bPrime.Greater(b) {
+		n.seenBallot(slot, bPrime)
+		code = http.StatusConflict
+	}
+	/* Random timeout for proposer to complete. */
+	n.timeout(proposalTimeoutLower, proposalTimeoutUpper, proposalTimeoutUnit)
+	return code
+}
+
+/* Proposer attempts to achieve quorum of promises from accepters for slot.
+ * Return (true, Ballot{}, k, v) if quorum reached and no promise carried an accepted value, or
  *
- * return (false, N' > N, v') where value-prime is this nodes' commited value
- * if any acceptor promised to a higher proposal number than N, or
+ * return (true, b', k', v') where value-prime of kind k' is the accepted
+ * value with the highest ballot b' among promises, if quorum reached, or
  *
- * return (false, N' > N, v') where value-prime is a new accepted value
- * if any acceptor accepted a higher proposal number than N.
+ * return (false, b' >= b, k, nil) where b' is the highest ballot any acceptor
+ * promised to, if quorum was not reached.
  */
-func (n *Node) Prepare(N int, v string) (bool, int, string) {
-	promises := make(chan *Promise, len(n.network))
+func (n *Node) Prepare(ctx context.Context, slot int, b Ballot, k Kind, v []byte) (bool, Ballot, Kind, []byte) {
+	c := n.config(slot)
+	promises := make(chan *Promise, len(c.Network))
+
+	ctx, span := startSpan(ctx, "paxos.prepare", slotAttributes(slot, b)...)
+	defer span.End()
 
 	/* Fan-out. */
-	n.prepareFanOut(N, promises)
+	n.prepareFanOut(ctx, c, slot, b, promises)
 
 	/* Fan-in. */
-	quorum, N, v := n.prepareFanIn(N, v, promises)
+	start := time.Now()
+	quorum, bPrime, k, v := n.prepareFanIn(c, b, k, v, promises)
+	n.metrics.waited(phasePrepare, start, quorum)
+	span.SetAttributes(attribute.Bool("paxos.quorum", quorum))
+	if !bPrime.IsZero() {
+		span.SetAttributes(attribute.String("paxos.ballot_prime", bPrime.String()))
+	}
 
 	/* Prepare-phase complete. */
-	return quorum, N, v
+	return quorum, bPrime, k, v
 }
 
 /* Fan-out method for prepare.
- * Proposer concurrently POSTs to accepters to prepare proposal.
+ * Proposer concurrently sends prepare to accepters of configuration, each
+ * in a span of its own, child of any in ctx.
  */
-func (n *Node) prepareFanOut(N int, promises chan *Promise) {
+func (n *Node) prepareFanOut(ctx context.Context, c *Config, slot int, b Ballot, promises chan *Promise) {
 
 	/* Go routine. */
-	prepare := func(url string) {
-		p := newPromise()
-		/* POST with empty body. */
-		resp, err := http.Post(url, contentTypeBytes, n.body)
+	prepare := func(addr string) {
+		ctx, span := startSpan(ctx, "paxos.prepare.peer", attribute.String("paxos.peer", addr))
+		n.metrics.sent.WithLabelValues(phasePrepare).Inc()
+		p, err := n.transport.Prepare(ctx, addr, slot, b)
+		tracePromise(span, phasePrepare, b, p, err)
+		endSpan(span, err)
 		if err != nil {
-			p.err = err
-			goto done
-		}
-		defer resp.Body.Close()
-
-		if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+			p = newPromise()
 			p.err = err
 		}
-	done:
 		promises <- p
 	}
-	/* Post prepare to accepters. */
-	for addr, role := range n.network {
+	/* Send prepare to accepters. */
+	for addr, role := range c.Network {
 		if role != Accepter {
 			continue
 		}
-		url := util.HttpUrl(addr, "prepare", N)
-		go prepare(url)
+		go prepare(addr)
 	}
 }
 
 /* Fan-in method for prepare.
- * Proposer gathers at most a quorum of prepare-promises from accepters.
+ * Proposer gathers promises from accepters until a quorum promised to ballot b.
  *
- * Return (true, N, v) if no accepters had promised or accepted a higher proposal,
+ * Return (true, Ballot{}, k, v) if a quorum promised and none had accepted a proposal,
  * or
- * return (false, p.prepare, n.value) if any accepters had promised to a higher proposal,
+ * return (true, p.N, p.Kind, p.Value) where p is the promise with the highest
+ * accepted ballot, if a quorum promised and any had accepted a proposal,
  * or
- * return (false, p.N, p.value) if any accepters had accepted a higher proposal.
+ * return (false, p.Prepare, k, nil) where p is the promise with the highest
+ * promised ballot, if no quorum promised to ballot b.
  */
-func (n *Node) prepareFanIn(N int, v string, promises chan *Promise) (bool, int, string) {
+func (n *Node) prepareFanIn(c *Config, b Ballot, k Kind, v []byte, promises chan *Promise) (bool, Ballot, Kind, []byte) {
 
-	quorum := 0
-	for range n.network {
+	promised, accepted := b, Ballot{}
+	granted, fast := map[string]bool{}, map[string]int{}
+	for i := 0; i < c.LenRoles(Accepter); i++ {
 		p := <-promises
 		if p.err != nil {
 			log.Info(p.err)
 			continue
-		} else /* Promised to a higher proposal. */ if p.prepare >= N {
-			return false, p.prepare, n.value
+		} else /* Slot is compacted by accepter; catch up on values chosen. */ if p.compacted() {
+			n.catchUpCompacted(p)
+			continue
 
-		} else /* Accepted a higher proposal. */ if p.N >= N {
-			return false, p.N, p.value
+		} else /* Promised to a higher proposal. */ if p.Prepare.Greater(b) {
+			n.metrics.rejections.WithLabelValues(phasePrepare).Inc()
+			if p.Prepare.Greater(promised) {
+				promised = p.Prepare
+			}
+			continue
 
-		} else /* (p.prepare, p.N) < N, ergo acceptor promise to this proposal. */ {
-			quorum++
+		} else /* Promised to a leader. */ if p.Lease != nil && p.Lease.Ballot.ID != b.ID {
+			n.metrics.rejections.WithLabelValues(phasePrepare).Inc()
+			continue
+		}
+		/* p.Prepare <= b, ergo acceptor promise to this proposal.
+		 * Adopt accepted value with highest ballot among promises. */
+		if p.N.Greater(accepted) {
+			accepted, k, v = p.N, p.Kind, p.Value
+		}
+		/* Values accepted in a fast round may differ among promises. */
+		if p.N == fastBallot {
+			fast[string(p.Value)]++
 		}
-		/* End early if proposer attained quorum. */
-		if quorum >= n.quorum {
+		granted[p.From] = true
+		/* End early if proposer attained phase-1 quorum. */
+		if c.Quorums.Phase1(granted) {
 			break
 		}
 	}
 	/* Prepare phase complete? */
-	return quorum >= n.quorum, N, v
+	if !c.Quorums.Phase1(granted) {
+		return false, promised, k, nil
+	}
+	/* Adopt value possibly chosen in fast round; clients only propose values. */
+	if accepted == fastBallot {
+		k, v = KindValue, c.fastValue(fast, len(granted))
+	}
+	return true, accepted, k, v
 }
 
-/* Proposer attempts to commit proposal to accepters.
+/* Proposer attempts to have quorum of accepters accept proposal for slot.
+ * Return (true, b) if quorum accepted ballot b, or
+ *
+ * return (false, b' >= b) where b' is the highest ballot any acceptor
+ * promised to, if quorum was not reached.
  */
-func (n *Node) Accept(N int, v string) (int, string) {
-	promises := make(chan *Promise, len(n.network))
+func (n *Node) Accept(ctx context.Context, slot int, b Ballot, k Kind, v []byte) (bool, Ballot) {
+	c := n.config(slot)
+	promises := make(chan *Promise, len(c.Network))
+
+	ctx, span := startSpan(ctx, "paxos.accept", slotAttributes(slot, b)...)
+	defer span.End()
 
 	/* Fan-out method. */
-	n.acceptFanOut(N, v, promises)
+	n.acceptFanOut(ctx, c, slot, b, k, v, promises)
 
 	/* Fan-in. */
-	n.acceptFanIn(N, v, promises)
+	start := time.Now()
+	quorum, bPrime := n.acceptFanIn(c, slot, b, v, promises)
+	n.metrics.waited(phaseAccept, start, quorum)
+	span.SetAttributes(attribute.Bool("paxos.quorum", quorum))
+	if bPrime != b {
+		span.SetAttributes(attribute.String("paxos.ballot_prime", bPrime.String()))
+	}
+	b = bPrime
 
-	/* Accept-phase complete - proposal finished. */
-	return N, v
+	/* Accept-phase complete. */
+	return quorum, b
 }
 
 /* Fan-out method for accept.
- * Proposer concurrently POSTs to both accepters and learners.
+ * Proposer concurrently sends accept to accepters of configuration, which in turn notify learners.
+ * Every accept is sent in a span of its own, child of any in ctx.
  */
-func (n *Node) acceptFanOut(N int, v string, promises chan *Promise) {
+func (n *Node) acceptFanOut(ctx context.Context, c *Config, slot int, b Ballot, k Kind, v []byte, promises chan *Promise) {
 
 	/* Go routine. */
-	accept := func(url string) {
-		p := newPromise()
-		/* POST with empty body. */
-		resp, err := http.Post(url, contentTypeBytes, n.body)
+	accept := func(addr string) {
+		ctx, span := startSpan(ctx, "paxos.accept.peer", attribute.String("paxos.peer", addr))
+		n.metrics.sent.WithLabelValues(phaseAccept).Inc()
+		p, err := n.transport.Accept(ctx, addr, slot, b, k, v)
+		tracePromise(span, phaseAccept, b, p, err)
+		endSpan(span, err)
 		if err != nil {
+			p = newPromise()
 			p.err = err
-			goto done
 		}
-		defer resp.Body.Close()
-
-		if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
-			p.err = err
-		}
-	done:
 		promises <- p
 	}
-	/* Update accpters and learners. */
-	for addr, role := range n.network {
-		if role != Accepter && role != Learner {
+	/* Update accpters. */
+	for addr, role := range c.Network {
+		if role != Accepter {
 			continue
 		}
-		url := util.HttpUrl(addr, "accept", N, v)
-		go accept(url)
+		go accept(addr)
 	}
 }
 
 /* Fan-in method for accept.
- * Proposer gathers accept-promises from accepters and learners.
- * Accepters and learners either commits, rejects, or are non-responsive.
- * Method is essentially non-functional and only evaluates accept phase.
+ * Proposer gathers accept-promises from accepters until
+ * a quorum of accepters accepted ballot b.
+ * Accepters either commits, rejects, or are non-responsive.
+ *
+ * Return (true, b) if a quorum of accepters accepted ballot b, or
+ *
+ * return (false, p.Prepare) where p is the promise with the highest
+ * promised ballot, if no quorum accepted ballot b.
  */
-func (n *Node) acceptFanIn(N int, v string, promises chan *Promise) {
+func (n *Node) acceptFanIn(c *Config, slot int, b Ballot, v []byte, promises chan *Promise) (bool, Ballot) {
 	summary := ""
 
-	quorum := 0
-	for _, role := range n.network {
-		if role != Accepter && role != Learner {
-			continue
-		}
+	promised := b
+	accepted := map[string]bool{}
+	for i := 0; i < c.LenRoles(Accepter); i++ {
 		p := <-promises
 		if p.err != nil {
 			log.Debug(p.err)
 			continue
-		} else /* Promised to a higher proposal. */ if p.prepare >= N {
-			summary += fmt.Sprintf("accept-promise from [%s] {prepare>=N : %d>=%d, values : [%s, %s]} \n",
-				p.from, p.prepare, N, p.value, v)
-
-		} else /* Accepted a higher proposal. */ if p.N >= N {
-			summary += fmt.Sprintf("accept-promise from [%s] {   N'>=N   : %d>=%d, values : [%s, %s]} \n",
-				p.from, p.N, N, p.value, v)
+		} else /* Slot is compacted by accepter; catch up on values chosen. */ if p.compacted() {
+			n.catchUpCompacted(p)
+			continue
 
-		} else /* (p.prepare, p.N) < N, ergo acceptor promise to this proposal. */ {
-			quorum++
+		} else /* Promised to a higher proposal. */ if p.Prepare.Greater(b) {
+			n.metrics.rejections.WithLabelValues(phaseAccept).Inc()
+			summary += fmt.Sprintf("accept-promise from [%s] {prepare>b : %s>%s, values : [%s, %s]} \n",
+				p.From, p.Prepare, b, p.Value, v)
+			if p.Prepare.Greater(promised) {
+				promised = p.Prepare
+			}
+
+		} else /* Accepted a higher proposal. */ if p.N.Greater(b) {
+			n.metrics.rejections.WithLabelValues(phaseAccept).Inc()
+			summary += fmt.Sprintf("accept-promise from [%s] {   b'>b   : %s>%s, values : [%s, %s]} \n",
+				p.From, p.N, b, p.Value, v)
+
+		} else /* Acceptor accepted this proposal. */ if p.N == b {
+			accepted[p.From] = true
+		}
+		/* End early if proposer attained phase-2 quorum. */
+		if c.Quorums.Phase2(accepted) {
+			break
 		}
 	}
-	summary = fmt.Sprintf("accept complete quorum := %d/%d for N := [%d] \n%s",
-		quorum, n.LenRoles(Accepter)+n.LenRoles(Learner), N, summary)
+	summary = fmt.Sprintf("accept complete quorum := %d/%d for slot := [%d], ballot := [%s] \n%s",
+		len(accepted), c.LenRoles(Accepter), slot, b, summary)
 
 	/* Accept phase complete. */
 	log.Debug(summary)
+
+	if !c.Quorums.Phase2(accepted) {
+		return false, promised
+	}
+	return true, b
 }
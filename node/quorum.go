@@ -0,0 +1,234 @@
+package paxos
+
+import (
+	"errors"
+
+	"github.com/marius-j-i/paxos/util"
+)
+
+var (
+	/* Errors. */
+	errNoQuorum        = errors.New("cannot achieve quorum with [%d] accepters")
+	errQuorumSize      = errors.New("quorum size [%d] not within [1, %d]")
+	errQuorumIntersect = errors.New("phase-1 quorum [%d] and phase-2 quorum [%d] do not intersect within [%d]")
+	errQuorumWeight    = errors.New("accepter [%s] has weight [%d]; expected a weight of at least zero")
+	errQuorumMember    = errors.New("[%s] is not an accepter in network")
+	errQuorumMissing   = errors.New("accepter [%s] is not part of quorum system")
+	errQuorumDuplicate = errors.New("accepter [%s] appears more than once in quorum system")
+	errGridEmptyRow    = errors.New("grid row [%d] has no accepters")
+
+	/* Quorum sizes for counting quorum system; zero is a majority of accepters. */
+	phase1Quorum = 0 // accepters needed to promise in prepare-phase
+	phase2Quorum = 0 // accepters needed to accept in accept-phase
+
+	/* Quorum system for nodes created hereafter; nil is a counting quorum system. */
+	quorumSystem QuorumSystem = nil
+)
+
+/* Quorum system decides which sets of accepters form a quorum in either phase.
+ * Every phase-1 quorum must intersect every phase-2 quorum.
+ */
+type QuorumSystem interface {
+	Phase1(accepters map[string]bool) bool // true if accepters form a phase-1 quorum
+	Phase2(accepters map[string]bool) bool // true if accepters form a phase-2 quorum
+	Validate(accepters []string) error     // non-nil if quorums do not intersect among accepters
+}
+
+/* Set phase-1 and phase-2 quorum sizes for nodes created hereafter.
+ * Sizes must satisfy q1 + q2 > accepters so every pair of quorums intersect.
+ * A size of zero is a majority of accepters.
+ */
+func SetQuorums(q1, q2 int) {
+	phase1Quorum, phase2Quorum, quorumSystem = q1, q2, nil
+}
+
+/* Set quorum system for nodes created hereafter.
+ * A nil quorum system counts accepters with sizes from SetQuorums.
+ */
+func SetQuorumSystem(q QuorumSystem) {
+	quorumSystem = q
+}
+
+/* Return quorum system for accepters in network, and assert its quorums intersect.
+ */
+func newQuorumSystem(accepters []string) (QuorumSystem, error) {
+
+	if len(accepters) < 1 {
+		return nil, util.ErrorFormat(errNoQuorum, len(accepters))
+	}
+	q := quorumSystem
+	if q == nil {
+		q = NewCounting(phase1Quorum, phase2Quorum, len(accepters))
+	}
+	if err := q.Validate(accepters); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+/* Counting quorum system.
+ * Any Q1 accepters form a phase-1 quorum, and any Q2 accepters a phase-2 quorum.
+ */
+type Counting struct {
+	Q1 int `json:"q1"` // accepters needed to promise
+	Q2 int `json:"q2"` // accepters needed to accept
+}
+
+/* Return counting quorum system for a number of accepters.
+ * A size of zero is a majority of accepters.
+ */
+func NewCounting(q1, q2, accepters int) *Counting {
+	if q1 == 0 {
+		q1 = (accepters / 2) + 1
+	}
+	if q2 == 0 {
+		q2 = (accepters / 2) + 1
+	}
+	return &Counting{Q1: q1, Q2: q2}
+}
+
+func (c *Counting) Phase1(accepters map[string]bool) bool {
+	return len(accepters) >= c.Q1
+}
+
+func (c *Counting) Phase2(accepters map[string]bool) bool {
+	return len(accepters) >= c.Q2
+}
+
+/* Assert quorums are attainable, and that Q1 + Q2 > accepters.
+ */
+func (c *Counting) Validate(accepters []string) error {
+	for _, q := range []int{c.Q1, c.Q2} {
+		if q < 1 || q > len(accepters) {
+			return util.ErrorFormat(errQuorumSize, q, len(accepters))
+		}
+	}
+	if c.Q1+c.Q2 <= len(accepters) {
+		return util.ErrorFormat(errQuorumIntersect, c.Q1, c.Q2, len(accepters))
+	}
+	return nil
+}
+
+/* Weighted quorum system.
+ * Accepters with a total weight of Q1 form a phase-1 quorum,
+ * and accepters with a total weight of Q2 a phase-2 quorum.
+ */
+type Weighted struct {
+	Weights map[string]int `json:"weights"` // address mapping to vote weight of accepter
+	Q1      int            `json:"q1"`      // weight needed to promise
+	Q2      int            `json:"q2"`      // weight needed to accept
+}
+
+/* Return total weight of accepters.
+ */
+func (w *Weighted) weight(accepters map[string]bool) (weight int) {
+	for a := range accepters {
+		weight += w.Weights[a]
+	}
+	return
+}
+
+func (w *Weighted) Phase1(accepters map[string]bool) bool {
+	return w.weight(accepters) >= w.Q1
+}
+
+func (w *Weighted) Phase2(accepters map[string]bool) bool {
+	return w.weight(accepters) >= w.Q2
+}
+
+/* Assert every accepter has a weight, quorums are attainable,
+ * and that Q1 + Q2 exceeds the total weight of accepters.
+ */
+func (w *Weighted) Validate(accepters []string) error {
+
+	total, members := 0, map[string]bool{}
+	for _, a := range accepters {
+		weight, ok := w.Weights[a]
+		if !ok {
+			return util.ErrorFormat(errQuorumMissing, a)
+		} else if weight < 0 {
+			return util.ErrorFormat(errQuorumWeight, a, weight)
+		}
+		total += weight
+		members[a] = true
+	}
+	for a := range w.Weights {
+		if !members[a] {
+			return util.ErrorFormat(errQuorumMember, a)
+		}
+	}
+	for _, q := range []int{w.Q1, w.Q2} {
+		if q < 1 || q > total {
+			return util.ErrorFormat(errQuorumSize, q, total)
+		}
+	}
+	if w.Q1+w.Q2 <= total {
+		return util.ErrorFormat(errQuorumIntersect, w.Q1, w.Q2, total)
+	}
+	return nil
+}
+
+/* Grid quorum system.
+ * Every accepter in any one row form a phase-1 quorum,
+ * and one accepter from every row form a phase-2 quorum.
+ */
+type Grid struct {
+	Rows [][]string `json:"rows"` // addresses of accepters in each row
+}
+
+func (g *Grid) Phase1(accepters map[string]bool) bool {
+	for _, row := range g.Rows {
+		all := true
+		for _, a := range row {
+			all = all && accepters[a]
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Grid) Phase2(accepters map[string]bool) bool {
+	for _, row := range g.Rows {
+		hit := false
+		for _, a := range row {
+			hit = hit || accepters[a]
+		}
+		if !hit {
+			return false
+		}
+	}
+	return true
+}
+
+/* Assert every accepter is in exactly one row, and every row is non-empty.
+ * Then any row intersects a set with one accepter from every row.
+ */
+func (g *Grid) Validate(accepters []string) error {
+
+	members := map[string]bool{}
+	for _, a := range accepters {
+		members[a] = true
+	}
+	seen := map[string]bool{}
+	for i, row := range g.Rows {
+		if len(row) == 0 {
+			return util.ErrorFormat(errGridEmptyRow, i)
+		}
+		for _, a := range row {
+			if !members[a] {
+				return util.ErrorFormat(errQuorumMember, a)
+			} else if seen[a] {
+				return util.ErrorFormat(errQuorumDuplicate, a)
+			}
+			seen[a] = true
+		}
+	}
+	for _, a := range accepters {
+		if !seen[a] {
+			return util.ErrorFormat(errQuorumMissing, a)
+		}
+	}
+	return nil
+}
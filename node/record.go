@@ -0,0 +1,68 @@
+package paxos
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+
+	"github.com/marius-j-i/paxos/util"
+)
+
+var (
+	/* Errors. */
+	errRecord        = errors.New("record [%s] is corrupt: %s")
+	errChecksum      = errors.New("record [%s] is corrupt: checksum [%08x] does not match [%08x] of its state")
+	errSchemaVersion = errors.New("record [%s] has schema version [%d]; expected version [%d]")
+
+	/* Persisted records. */
+	schemaVersion = 1                                 // version of encoding of state in records
+	crcTable      = crc32.MakeTable(crc32.Castagnoli) // checksums are CRC-32C
+)
+
+/* Envelope of state persisted by storage. Records carry the schema version
+ * state was encoded with, and a checksum of the encoding, so state corrupted
+ * on disk, or written by another version, is refused on restore.
+ */
+type record struct {
+	Version  int             `json:"version"`  // schema version of state
+	Checksum uint32          `json:"checksum"` // checksum of state
+	State    json.RawMessage `json:"state"`    // state encoded as json
+}
+
+/* Return record of v encoded as json.
+ */
+func encodeRecord(v interface{}) ([]byte, error) {
+	state, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&record{
+		Version:  schemaVersion,
+		Checksum: crc32.Checksum(state, crcTable),
+		State:    state,
+	})
+}
+
+/* Decode state of record b, named by name in errors, into v.
+ * Return error if record is not valid json, has another schema version,
+ * does not match its checksum, or its state has fields v does not.
+ */
+func decodeRecord(name string, b []byte, v interface{}) error {
+
+	r := record{}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return util.ErrorFormat(errRecord, name, err)
+	} else if r.Version != schemaVersion {
+		return util.ErrorFormat(errSchemaVersion, name, r.Version, schemaVersion)
+	} else if sum := crc32.Checksum(r.State, crcTable); sum != r.Checksum {
+		return util.ErrorFormat(errChecksum, name, r.Checksum, sum)
+	}
+	/* Fields of the wrong type, or unknown to this version, are not skipped. */
+	dec := json.NewDecoder(bytes.NewReader(r.State))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return util.ErrorFormat(errRecord, name, err)
+	}
+	return nil
+}
@@ -13,10 +13,17 @@ var (
 
 	/* Json body keywords. */
 	JsonKeyAccepted  = `accepted`
+	JsonKeyChosen    = `chosen`
 	JsonKeyProposal  = `proposal`
 	JsonKeyPrepare   = `prepare`
+	JsonKeySlot      = `slot`
+	JsonKeyLog       = `log`
+	JsonKeyRound     = `round`
+	JsonKeyID        = `id`
 	JsonKeyAccepters = `accepters`
 	JsonKeyLearners  = `learners`
+	JsonKeyValue     = `value`
+	JsonKeyKind      = `kind`
 
 	/* Empty buffer to signal alive. */
 	alive = []byte{}
@@ -29,10 +36,78 @@ var (
 func (n *Node) GetAccepted(w http.ResponseWriter, req *http.Request) {
 	defer req.Body.Close()
 
-	body := map[string]interface{}{
-		JsonKeyAccepted: n.value,
-		JsonKeyProposal: n.N,
-		JsonKeyPrepare:  n.prepare,
+	/* Most recent slot with an accepted value. */
+	n.respondSlots(w, req, n.lastSlot(), n.lastSlot(), false)
+}
+
+/* /accepted/{slot}
+ * Role - Any
+ */
+
+func (n *Node) GetAcceptedSlot(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	slot, err := n.getVarInt(req, varSlot)
+	if err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	n.respondSlots(w, req, slot, slot, false)
+}
+
+/* /accepted/{from}/{to}
+ * Role - Any
+ */
+
+func (n *Node) GetAcceptedRange(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	from, err := n.getVarInt(req, varFrom)
+	if err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	to, err := n.getVarInt(req, varTo)
+	if err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	n.respondSlots(w, req, from, to, true)
+}
+
+/* Respond with json-body for slots within [from, to].
+ * Body is a single slot-entry, or if asRange, an entry-list under JsonKeyLog.
+ */
+func (n *Node) respondSlots(w http.ResponseWriter, req *http.Request, from, to int, asRange bool) {
+
+	/* Slot-entry from slot index. */
+	entry := func(i int) map[string]interface{} {
+		s := n.peekSlot(i)
+		return map[string]interface{}{
+			JsonKeySlot:     i,
+			JsonKeyAccepted: s.Value,
+			JsonKeyProposal: s.N,
+			JsonKeyPrepare:  s.Prepare,
+			JsonKeyKind:     kindNames[s.Kind],
+		}
+	}
+
+	var body interface{}
+	if !asRange {
+		body = entry(from)
+
+	} else if slots, err := n.slots(from, to); err != nil {
+		n.respondError(w, http.StatusBadRequest, err.Error())
+		return
+
+	} else {
+		entries := make([]map[string]interface{}, len(slots))
+		for i := range slots {
+			entries[i] = entry(slots[i])
+		}
+		body = map[string]interface{}{
+			JsonKeyLog: entries,
+		}
 	}
 	/* Write before 200 OK is set on write. */
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,316 @@
+package paxos
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/marius-j-i/paxos/util"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	/* Errors. */
+	errNoSnapshot = errors.New("no snapshot taken by [%s]")
+	errCompacted  = errors.New("slot [%d] is compacted into snapshot of slot [%d]")
+
+	/* Globals. */
+	snapshotInterval = 0 // on n > 0; nodes take a snapshot every n slots applied, and compact log
+)
+
+/* State machine replicated by the log. Nodes apply every chosen value to it
+ * exactly once, in slot order, and snapshot it to compact their log.
+ */
+type Application interface {
+	/* Apply value v chosen for slot. */
+	Apply(slot int, v []byte) error
+
+	/* Return encoding of state after every value applied so far. */
+	Snapshot() ([]byte, error)
+
+	/* Replace state with state encoded by Snapshot. */
+	Restore(state []byte) error
+}
+
+/* Application state after applying every value chosen up to and including slot.
+ * Slots up to it are compacted from log and storage.
+ */
+type Snapshot struct {
+	Slot    int             `json:"slot"`    // last slot applied to state
+	State   []byte          `json:"state"`   // application state, or nil if node has no application
+	Changes map[int]*Change `json:"changes"` // membership changes chosen up to slot
+}
+
+/* Set number of slots applied between snapshots; zero disables snapshots.
+ */
+func SetSnapshotInterval(slots int) {
+	snapshotInterval = slots
+}
+
+/* Set application chosen values are applied to, and restore it from latest
+ * snapshot. Values chosen after snapshot are applied before returning.
+ * Set application before serving node, whenever node is created.
+ */
+func (n *Node) SetApplication(app Application) error {
+	n.applying.Lock()
+
+	n.mu.Lock()
+	snap := n.snapshot
+	n.applied = n.snapshotSlotLocked()
+	n.mu.Unlock()
+
+	if snap != nil {
+		if err := app.Restore(snap.State); err != nil {
+			n.applying.Unlock()
+			return err
+		}
+	}
+	n.app = app
+	n.applying.Unlock()
+
+	return n.apply()
+}
+
+/* Apply values chosen for slots after last applied slot, in order, until a
+ * slot is not yet chosen. Membership changes are applied to configuration
+ * as they are chosen, not to application. Take a snapshot once
+ * snapshotInterval slots were applied since the latest.
+ */
+func (n *Node) apply() error {
+	n.applying.Lock()
+	defer n.applying.Unlock()
+
+	for {
+		n.mu.Lock()
+		i := n.applied + 1
+		s, ok := n.log[i]
+		chosen := ok && s.Chosen
+		var v []byte
+		var k Kind
+		if chosen {
+			v, k = s.Value, s.Kind
+		}
+		n.mu.Unlock()
+
+		if !chosen {
+			break
+		}
+		if n.app != nil && k == KindValue {
+			if err := n.app.Apply(i, v); err != nil {
+				return err
+			}
+		}
+		n.mu.Lock()
+		n.applied = i
+		n.mu.Unlock()
+	}
+
+	n.mu.Lock()
+	due := snapshotInterval > 0 && n.applied-n.snapshotSlotLocked() >= snapshotInterval
+	n.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+	return n.takeSnapshot()
+}
+
+/* Snapshot application at last applied slot, save snapshot, and compact log.
+ * Caller holds n.applying.
+ */
+func (n *Node) takeSnapshot() error {
+
+	var state []byte
+	if n.app != nil {
+		var err error
+		if state, err = n.app.Snapshot(); err != nil {
+			return err
+		}
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	snap := &Snapshot{
+		Slot:    n.applied,
+		State:   state,
+		Changes: map[int]*Change{},
+	}
+	for i, ch := range n.changes {
+		if i <= snap.Slot {
+			snap.Changes[i] = ch
+		}
+	}
+	/* Slots are removed from storage only once snapshot is durable. */
+	if err := n.storage.SaveSnapshot(snap); err != nil {
+		return err
+	}
+	n.synced = time.Now()
+	n.compactLocked(snap)
+
+	log.Infof("[%s] took snapshot of slot [%d]", n.server.Addr, snap.Slot)
+	return nil
+}
+
+/* Install snapshot from a peer, if it is ahead of values applied by node.
+ * Application is restored from snapshot, which is saved and compacts log.
+ */
+func (n *Node) install(snap *Snapshot) error {
+	n.applying.Lock()
+	defer n.applying.Unlock()
+
+	n.mu.Lock()
+	applied := n.applied
+	n.mu.Unlock()
+
+	if snap.Slot <= applied {
+		return nil
+	}
+	if n.app != nil {
+		if err := n.app.Restore(snap.State); err != nil {
+			return err
+		}
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if err := n.storage.SaveSnapshot(snap); err != nil {
+		return err
+	}
+	n.synced = time.Now()
+	n.applied = snap.Slot
+	n.compactLocked(snap)
+
+	/* Membership changes compacted from log are known from snapshot. */
+	for i, ch := range snap.Changes {
+		n.changes[i] = ch
+	}
+	n.reconfigure()
+	n.windowed.Broadcast()
+
+	log.Infof("[%s] installed snapshot of slot [%d]", n.server.Addr, snap.Slot)
+	return nil
+}
+
+/* Set snapshot as latest, and remove slots up to its slot from log.
+ * Caller holds n.mu.
+ */
+func (n *Node) compactLocked(snap *Snapshot) {
+	n.snapshot = snap
+	for i := range n.log {
+		if i <= snap.Slot {
+			delete(n.log, i)
+		}
+	}
+	for i := range n.tally {
+		if i <= snap.Slot {
+			delete(n.tally, i)
+		}
+	}
+}
+
+/* Return slot of latest snapshot, or firstSlot-1 if none was taken.
+ * Caller holds n.mu.
+ */
+func (n *Node) snapshotSlotLocked() int {
+	if n.snapshot == nil {
+		return firstSlot - 1
+	}
+	return n.snapshot.Slot
+}
+
+/* Return true if slot is compacted into latest snapshot.
+ */
+func (n *Node) compacted(slot int) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return slot <= n.snapshotSlotLocked()
+}
+
+/* Return true if log grew far beyond latest snapshot, such that node lags
+ * behind its peers, or only learns of snapshots from them, as accepters do.
+ * Caller holds n.mu.
+ */
+func (n *Node) laggingLocked() bool {
+	return snapshotInterval > 0 && len(n.log) > 2*snapshotInterval
+}
+
+/* Install greatest snapshot among members if it is ahead of node, and apply
+ * values chosen after it. Only one catch-up runs at a time.
+ */
+func (n *Node) catchUp() {
+	defer func() {
+		n.mu.Lock()
+		n.catchingUp = false
+		n.mu.Unlock()
+	}()
+
+	var latest *Snapshot
+	for addr := range n.members() {
+		snap, err := n.transport.Snapshot(addr)
+		if err != nil {
+			log.Debug(err)
+			continue
+		}
+		if latest == nil || snap.Slot > latest.Slot {
+			latest = snap
+		}
+	}
+	if latest == nil {
+		return
+	}
+	if err := n.install(latest); err != nil {
+		log.Error(err)
+	} else if err := n.apply(); err != nil {
+		log.Error(err)
+	}
+}
+
+/* Install snapshot of accepter that refused promise p since slot is compacted,
+ * and apply values chosen after it, unless slot is already compacted by node.
+ */
+func (n *Node) catchUpCompacted(p *Promise) {
+	if n.compacted(p.Slot) {
+		return
+	}
+	snap, err := n.transport.Snapshot(p.From)
+	if err != nil {
+		log.Error(err)
+	} else if err := n.install(snap); err != nil {
+		log.Error(err)
+	} else if err := n.apply(); err != nil {
+		log.Error(err)
+	}
+}
+
+/* Return latest snapshot, or nil if none was taken.
+ * Snapshots are never modified once taken.
+ */
+func (n *Node) latestSnapshot() *Snapshot {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.snapshot
+}
+
+/* /snapshot
+ * Role - Any
+ */
+
+func (n *Node) GetSnapshot(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	snap := n.latestSnapshot()
+	if snap == nil {
+		err := util.ErrorFormat(errNoSnapshot, n.server.Addr)
+		n.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	/* Write before 200 OK is set on write. */
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(snap); err != nil {
+		log.Error(err)
+	}
+}
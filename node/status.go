@@ -0,0 +1,295 @@
+package paxos
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	/* Weight of latest round trip in smoothed round-trip time of peers. */
+	rttWeight = 0.125
+)
+
+/* Summary of node, telling operators what node promised, accepted and
+ * persisted, and how well it reaches its peers.
+ * Body of response to /status.
+ */
+type Status struct {
+	Role      string          `json:"role"`      // role of node
+	Addr      string          `json:"addr"`      // address of node
+	ID        int             `json:"id"`        // id of node, issued in its ballots
+	Leader    string          `json:"leader"`    // proposer holding a valid lease, as known by node; empty if none
+	System    string          `json:"system"`    // kind of quorum system; counting, weighted or grid
+	Quorums   json.RawMessage `json:"quorums"`   // quorum sizes, or weights, or rows, of quorum system
+	Fast      int             `json:"fast"`      // accepters needed to choose a value in a fast round
+	Accepters int             `json:"accepters"` // accepters in network, node included
+	Promised  Ballot          `json:"promised"`  // highest ballot promised in any slot, or by lease
+	Accepted  Ballot          `json:"accepted"`  // highest ballot of any value accepted
+	Slot      int             `json:"slot"`      // most recent slot with an accepted value
+	Chosen    int             `json:"chosen"`    // most recent slot with a value known to be chosen
+	Digest    string          `json:"digest"`    // SHA-256 of values accepted after latest snapshot, in slot order
+	Peers     map[string]Peer `json:"peers"`     // address mapping to network member, as last seen by node
+	Storage   StorageStatus   `json:"storage"`   // where, and when, state was last made durable
+	InFlight  int             `json:"in_flight"` // proposals in progress on node
+}
+
+/* Status and internals of node.
+ * Body of response to /debug/state.
+ */
+type State struct {
+	Status
+	Ballot    Ballot     `json:"ballot"`    // highest ballot seen; new proposals exceed it
+	Lease     *Lease     `json:"lease"`     // lease granted, held or learned by node, if any
+	Snapshot  int        `json:"snapshot"`  // slot of latest snapshot; zero if none
+	Applied   int        `json:"applied"`   // last slot applied to application
+	Reserved  []int      `json:"reserved"`  // slots reserved by proposals in progress
+	Proposals []InFlight `json:"proposals"` // proposals in progress, oldest first
+}
+
+/* Network member, as last seen by node. */
+type Peer struct {
+	Role      string    `json:"role"`                 // role of member
+	LastSeen  time.Time `json:"last_seen,omitzero"`   // time member last replied to a message
+	RTT       float64   `json:"rtt_ms"`               // smoothed round-trip time of messages to member, in milliseconds
+	LastError string    `json:"last_error,omitempty"` // error of latest message that failed to reach member
+	FailedAt  time.Time `json:"failed_at,omitzero"`   // time of latest message that failed
+}
+
+/* Storage of node state. */
+type StorageStatus struct {
+	Path   string    `json:"path"`            // file state is kept in; empty if kept in memory
+	Synced time.Time `json:"synced,omitzero"` // time state was last made durable
+}
+
+/* Proposal in progress on proposer. */
+type InFlight struct {
+	Slot      int       `json:"slot"`      // slot of current attempt
+	Attempt   int       `json:"attempt"`   // current attempt, of at most maxProposals
+	Started   time.Time `json:"started"`   // time proposal began
+	Size      int       `json:"size"`      // bytes of value proposed
+	Forwarded bool      `json:"forwarded"` // true if forwarded by another proposer
+}
+
+/* /status
+ * Role - Any
+ */
+
+func (n *Node) GetStatus(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	n.respondState(w, req, &n.state().Status)
+}
+
+/* /debug/state
+ * Role - Any
+ */
+
+func (n *Node) GetDebugState(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	n.respondState(w, req, n.state())
+}
+
+/* Respond with json-body of state.
+ */
+func (n *Node) respondState(w http.ResponseWriter, req *http.Request, state interface{}) {
+
+	body, err := json.Marshal(state)
+	if err != nil {
+		msg := fmt.Sprintf("unable to encode response to [%s]: \n%s", req.URL, err.Error())
+		n.respondError(w, http.StatusInternalServerError, msg)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(append(body, '\n'))
+}
+
+/* Return state of node, as seen at once.
+ */
+func (n *Node) state() *State {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	last := n.lastSlotLocked()
+	c := n.configLocked(last + 1)
+	quorums, _ := json.Marshal(c.Quorums)
+	accepters := c.LenRoles(Accepter)
+	if c.Role == Accepter {
+		accepters++
+	}
+
+	s := &State{
+		Status: Status{
+			Role:      roleNames[c.Role],
+			Addr:      n.server.Addr,
+			ID:        n.id,
+			System:    quorumName(c.Quorums),
+			Quorums:   quorums,
+			Fast:      c.Fast,
+			Accepters: accepters,
+			Promised:  n.highestPromiseLocked(),
+			Slot:      last,
+			Chosen:    n.lastChosenLocked(),
+			Digest:    n.digestLocked(),
+			Peers:     n.peers.table(c.Network),
+			Storage:   StorageStatus{Path: n.storage.Path(), Synced: n.synced},
+			InFlight:  len(n.proposals),
+		},
+		Ballot:    n.ballot,
+		Snapshot:  n.snapshotSlotLocked(),
+		Applied:   n.applied,
+		Reserved:  []int{},
+		Proposals: []InFlight{},
+	}
+	if n.lease.valid() {
+		s.Leader = n.lease.Leader
+	}
+	if n.lease != nil {
+		l := *n.lease
+		s.Lease = &l
+	}
+	for _, slot := range n.log {
+		if slot.N.Greater(s.Accepted) {
+			s.Accepted = slot.N
+		}
+	}
+	for i := range n.inflight {
+		s.Reserved = append(s.Reserved, i)
+	}
+	sort.Ints(s.Reserved)
+	for p := range n.proposals {
+		s.Proposals = append(s.Proposals, *p)
+	}
+	sort.Slice(s.Proposals, func(i, j int) bool {
+		return s.Proposals[i].Started.Before(s.Proposals[j].Started)
+	})
+	return s
+}
+
+/* Return name of kind of quorum system q.
+ */
+func quorumName(q QuorumSystem) string {
+	switch q.(type) {
+	case *Counting:
+		return "counting"
+	case *Weighted:
+		return "weighted"
+	case *Grid:
+		return "grid"
+	}
+	return fmt.Sprintf("%T", q)
+}
+
+/* Return hex-encoded SHA-256 of values accepted after latest snapshot, in
+ * slot order, so nodes may be compared by digest.
+ * Caller holds n.mu.
+ */
+func (n *Node) digestLocked() string {
+	indices := []int{}
+	for i, s := range n.log {
+		if !s.N.IsZero() {
+			indices = append(indices, i)
+		}
+	}
+	sort.Ints(indices)
+
+	h := sha256.New()
+	for _, i := range indices {
+		v := n.log[i].Value
+		binary.Write(h, binary.BigEndian, [2]int64{int64(i), int64(len(v))})
+		h.Write(v)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+/* Track proposal for value v as in progress until endProposal.
+ */
+func (n *Node) startProposal(v []byte, forwarded bool) *InFlight {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	p := &InFlight{Started: time.Now(), Size: len(v), Forwarded: forwarded}
+	n.proposals[p] = true
+	return p
+}
+
+/* Record proposal making attempt for slot.
+ */
+func (n *Node) attempting(p *InFlight, slot, attempt int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	p.Slot, p.Attempt = slot, attempt
+}
+
+/* Stop tracking proposal.
+ */
+func (n *Node) endProposal(p *InFlight) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	delete(n.proposals, p)
+}
+
+/* Network members as last seen through transport of node.
+ */
+type peers struct {
+	seen map[string]*Peer // address mapping to member seen
+	mu   sync.Mutex       // guards seen
+}
+
+/* Return empty record of members seen.
+ */
+func newPeers() *peers {
+	return &peers{seen: map[string]*Peer{}}
+}
+
+/* Record member at address replying to a message after rtt, or failing
+ * to reply with err.
+ */
+func (p *peers) record(addr string, rtt time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	peer, ok := p.seen[addr]
+	if !ok {
+		peer = &Peer{}
+		p.seen[addr] = peer
+	}
+	if err != nil {
+		peer.LastError, peer.FailedAt = err.Error(), time.Now()
+		return
+	}
+	ms := float64(rtt) / float64(time.Millisecond)
+	if peer.LastSeen.IsZero() {
+		peer.RTT = ms
+	} else {
+		peer.RTT += rttWeight * (ms - peer.RTT)
+	}
+	peer.LastSeen = time.Now()
+}
+
+/* Return members of network as last seen, including members never seen.
+ */
+func (p *peers) table(network map[string]Role) map[string]Peer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	table := make(map[string]Peer, len(network))
+	for addr, role := range network {
+		peer := Peer{}
+		if seen, ok := p.seen[addr]; ok {
+			peer = *seen
+		}
+		peer.Role = roleNames[role]
+		table[addr] = peer
+	}
+	return table
+}
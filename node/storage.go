@@ -0,0 +1,378 @@
+package paxos
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	/* Errors. */
+	errNoState = errors.New("no state saved")
+
+	/* Files. */
+	tmpSuffix    = ".tmp" // suffix of file new state is written to before replacing previous state
+	compactAfter = 1024   // records appended to file storage before its file is rewritten whole
+)
+
+/* Durable state of a node; the promise, accepted ballot and value of every
+ * slot in its log, and the lease it granted.
+ * Accepters reply to proposers only after state is saved, so storage must
+ * keep what it saved across crashes.
+ */
+type Storage interface {
+	/* Save slots and lease, replacing what was saved for those slots and
+	 * any lease saved before. State is durable on return. */
+	Save(slots map[int]Slot, lease *Lease) error
+
+	/* Return log and lease saved, or errNoState if nothing was saved. */
+	Load() (map[int]*Slot, *Lease, error)
+
+	/* Save snapshot, replacing any saved before, and remove slots saved up to
+	 * and including its slot. State is durable on return. */
+	SaveSnapshot(snap *Snapshot) error
+
+	/* Return snapshot saved, or nil if none was saved. */
+	LoadSnapshot() (*Snapshot, error)
+
+	/* Return path of file state is saved in, or "" if not saved in a file. */
+	Path() string
+
+	/* Release storage, keeping state saved. */
+	Close() error
+
+	/* Release storage, and remove state saved. */
+	Remove() error
+}
+
+/* State as encoded by file storage.
+ */
+type fileState struct {
+	Log      map[int]*Slot `json:"log"`      // slot index mapping to slot
+	Lease    *Lease        `json:"lease"`    // lease granted, if any
+	Snapshot *Snapshot     `json:"snapshot"` // latest snapshot, if any; log holds only slots after it
+}
+
+/* Storage keeping state as json in a single file, as a sequence of records,
+ * one per line. The first record holds state whole, and every save appends
+ * a record of only the slots and lease it saved, so a save costs the size of
+ * its slots rather than of the log. Snapshots, the first save after the file
+ * is opened, and every compactAfter records appended, rewrite the file whole
+ * by atomically replacing it, so the file stays bounded.
+ */
+type FileStorage struct {
+	path     string     // file state is kept in
+	state    fileState  // state saved, as of every record in file
+	file     *os.File   // file opened for appending records; nil until rewritten whole
+	appended int        // records appended to file since it was rewritten whole
+	mu       sync.Mutex // guards state, file and appended
+}
+
+/* Return storage keeping state in file at path. Directories are created.
+ */
+func NewFileStorage(path string) (*FileStorage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &FileStorage{
+		path:  path,
+		state: fileState{Log: map[int]*Slot{}},
+	}, nil
+}
+
+func (s *FileStorage) Save(slots map[int]Slot, lease *Lease) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := fileState{Log: make(map[int]*Slot, len(slots)), Lease: lease}
+	for i := range slots {
+		slot := slots[i]
+		s.state.Log[i], changed.Log[i] = &slot, &slot
+	}
+	s.state.Lease = lease
+
+	if s.file == nil || s.appended >= compactAfter {
+		return s.write()
+	}
+	return s.append(&changed)
+}
+
+func (s *FileStorage) Load() (map[int]*Slot, *Lease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.read(); err != nil {
+		return nil, nil, err
+	}
+	/* Caller owns returned slots. */
+	log := make(map[int]*Slot, len(s.state.Log))
+	for i, slot := range s.state.Log {
+		c := *slot
+		log[i] = &c
+	}
+	return log, s.state.Lease, nil
+}
+
+func (s *FileStorage) SaveSnapshot(snap *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.state.Log {
+		if i <= snap.Slot {
+			delete(s.state.Log, i)
+		}
+	}
+	s.state.Snapshot = snap
+
+	return s.write()
+}
+
+func (s *FileStorage) LoadSnapshot() (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.read(); errors.Is(err, errNoState) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return s.state.Snapshot, nil
+}
+
+/* Replace file with state whole, and open it for appending records.
+ * Caller holds s.mu.
+ */
+func (s *FileStorage) write() error {
+	if err := s.closeFile(); err != nil {
+		return err
+	}
+	b, err := encodeRecord(&s.state)
+	if err != nil {
+		return err
+	} else if err := replaceFile(s.path, append(b, '\n')); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	s.file, s.appended = f, 0
+	return nil
+}
+
+/* Append record of changed state to file, and sync it.
+ * On error, file is closed, so the next save rewrites it whole rather than
+ * append to a record left half-way.
+ * Caller holds s.mu.
+ */
+func (s *FileStorage) append(changed *fileState) error {
+	b, err := encodeRecord(changed)
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(b, '\n')); err != nil {
+		s.closeFile()
+		return err
+	} else if err := s.file.Sync(); err != nil {
+		s.closeFile()
+		return err
+	}
+	s.appended++
+	return nil
+}
+
+/* Replace state with state in file, or return errNoState if there is no file.
+ * Return error if file is not a valid sequence of records of state.
+ * Caller holds s.mu.
+ */
+func (s *FileStorage) read() error {
+
+	/* A temporary file is a write interrupted by a crash; its state
+	 * was never replied upon. */
+	if err := os.Remove(s.path + tmpSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return errNoState
+	} else if err != nil {
+		return err
+	}
+	records := bytes.Split(b, []byte("\n"))
+
+	/* First record is state whole, written by replacing file. */
+	state := fileState{}
+	if err := decodeRecord(s.path, records[0], &state); err != nil {
+		return err
+	} else if state.Log == nil {
+		state.Log = map[int]*Slot{}
+	}
+	for i, r := range records[1:] {
+
+		/* A last record without newline is an append interrupted by a
+		 * crash; its state was never replied upon. */
+		if i == len(records)-2 {
+			break
+		}
+		changed := fileState{}
+		if err := decodeRecord(s.path, r, &changed); err != nil {
+			return err
+		}
+		for j, slot := range changed.Log {
+			state.Log[j] = slot
+		}
+		state.Lease = changed.Lease
+	}
+	s.state = state
+	return nil
+}
+
+/* Close file opened for appending, if any.
+ * Caller holds s.mu.
+ */
+func (s *FileStorage) closeFile() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func (s *FileStorage) Path() string {
+	return s.path
+}
+
+func (s *FileStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closeFile()
+}
+
+func (s *FileStorage) Remove() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closeFile()
+	for _, path := range []string{s.path, s.path + tmpSuffix} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+/* Replace contents of file at path with b, such that a crash leaves either
+ * previous or new contents on disk, never a mix of both.
+ * New contents are written and synced to a temporary file, which is then
+ * renamed over file, and the rename synced through its directory.
+ */
+func replaceFile(path string, b []byte) error {
+
+	tmp := path + tmpSuffix
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	/* Rename is durable once directory is synced. */
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+/* Storage keeping state in memory only. State survives a node restarted
+ * with the same storage, but not the process.
+ */
+type MemoryStorage struct {
+	log      map[int]Slot // slot index mapping to slot saved
+	lease    *Lease       // lease saved, if any
+	snapshot *Snapshot    // snapshot saved, if any
+	saved    bool         // true once anything was saved
+	mu       sync.Mutex   // guards log, lease, snapshot and saved
+}
+
+/* Return new, empty in-memory storage.
+ */
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{log: map[int]Slot{}}
+}
+
+func (s *MemoryStorage) Save(slots map[int]Slot, lease *Lease) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, slot := range slots {
+		s.log[i] = slot
+	}
+	s.lease, s.saved = lease, true
+	return nil
+}
+
+func (s *MemoryStorage) Load() (map[int]*Slot, *Lease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.saved {
+		return nil, nil, errNoState
+	}
+	log := make(map[int]*Slot, len(s.log))
+	for i := range s.log {
+		slot := s.log[i]
+		log[i] = &slot
+	}
+	return log, s.lease, nil
+}
+
+func (s *MemoryStorage) SaveSnapshot(snap *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.log {
+		if i <= snap.Slot {
+			delete(s.log, i)
+		}
+	}
+	s.snapshot = snap
+	return nil
+}
+
+func (s *MemoryStorage) LoadSnapshot() (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.snapshot, nil
+}
+
+func (s *MemoryStorage) Path() string {
+	return ""
+}
+
+func (s *MemoryStorage) Close() error {
+	return nil
+}
+
+func (s *MemoryStorage) Remove() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.log, s.lease, s.snapshot, s.saved = map[int]Slot{}, nil, nil, false
+	return nil
+}
@@ -0,0 +1,171 @@
+package paxos
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/marius-j-i/paxos/util"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	/* Errors. */
+	errNoCertificates    = errors.New("no certificates found in [%s]")
+	errNoPeerCertificate = errors.New("peer presented no certificate")
+)
+
+/* Certificate and cluster CA of a member, read from PEM-files.
+ * Files are read again once modified, so rotated certificates are used for
+ * new connections without restarting nodes.
+ */
+type Credentials struct {
+	caFile, certFile, keyFile string // paths to PEM-files of cluster CA, certificate and its key
+
+	cert     *tls.Certificate // certificate presented to peers
+	roots    *x509.CertPool   // cluster CA peers are verified against
+	modified time.Time        // latest modification time of files when read
+	mu       sync.Mutex       // guards cert, roots and modified
+}
+
+/* Return credentials read from PEM-files of cluster CA, certificate and its key.
+ */
+func LoadCredentials(caFile, certFile, keyFile string) (*Credentials, error) {
+
+	c := &Credentials{caFile: caFile, certFile: certFile, keyFile: keyFile}
+	modified, err := c.lastModified()
+	if err != nil {
+		return nil, err
+	} else if err := c.load(modified); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+/* Return latest modification time of files.
+ */
+func (c *Credentials) lastModified() (time.Time, error) {
+	var last time.Time
+	for _, path := range []string{c.caFile, c.certFile, c.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		} else if info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+	return last, nil
+}
+
+/* Read files, and replace certificate and cluster CA.
+ */
+func (c *Credentials) load(modified time.Time) error {
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return err
+	}
+	pem, err := os.ReadFile(c.caFile)
+	if err != nil {
+		return err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return util.ErrorFormat(errNoCertificates, c.caFile)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cert, c.roots, c.modified = &cert, roots, modified
+	return nil
+}
+
+/* Return current certificate and cluster CA, reading files again if modified.
+ * Files are only partially written while rotated; previous credentials are
+ * kept until files read successfully.
+ */
+func (c *Credentials) current() (*tls.Certificate, *x509.CertPool) {
+
+	if modified, err := c.lastModified(); err != nil {
+		log.Error(err)
+	} else if c.stale(modified) {
+		if err := c.load(modified); err != nil {
+			log.Error(err)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.cert, c.roots
+}
+
+/* Return true if files were modified after credentials were read.
+ */
+func (c *Credentials) stale(modified time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return modified.After(c.modified)
+}
+
+/* Return configuration for servers of members, which require peers to
+ * present a certificate issued by cluster CA.
+ */
+func (c *Credentials) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.RequireAnyClientCert,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, _ := c.current()
+			return cert, nil
+		},
+		/* Verified against current cluster CA, instead of a fixed pool. */
+		VerifyConnection: c.verify(x509.ExtKeyUsageClientAuth),
+	}
+}
+
+/* Return configuration for clients of members, which present certificate
+ * and require servers to present a certificate issued by cluster CA.
+ */
+func (c *Credentials) ClientConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := c.current()
+			return cert, nil
+		},
+		/* Skips verification against a fixed pool only; servers are verified
+		 * against current cluster CA. */
+		InsecureSkipVerify: true,
+		VerifyConnection:   c.verify(x509.ExtKeyUsageServerAuth),
+	}
+}
+
+/* Return verifier of peer certificate chain against current cluster CA.
+ * Servers must also be valid for the name they were dialed by.
+ */
+func (c *Credentials) verify(usage x509.ExtKeyUsage) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+
+		if len(cs.PeerCertificates) == 0 {
+			return errNoPeerCertificate
+		}
+		_, roots := c.current()
+		opts := x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: x509.NewCertPool(),
+			KeyUsages:     []x509.ExtKeyUsage{usage},
+		}
+		if usage == x509.ExtKeyUsageServerAuth {
+			opts.DNSName = cs.ServerName
+		}
+		for _, cert := range cs.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := cs.PeerCertificates[0].Verify(opts)
+		return err
+	}
+}
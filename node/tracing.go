@@ -0,0 +1,177 @@
+package paxos
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+var (
+	/* Tracing. */
+	tracerName = "github.com/marius-j-i/paxos/node"
+	otlpPath   = "/v1/traces" // path of OTLP endpoints given without one
+
+	/* Trace context of peer messages travels in W3C traceparent headers. */
+	propagator = propagation.TraceContext{}
+
+	/* Outcomes of messages to accepters, as span attribute paxos.outcome. */
+	outcomePromised  = "promised"  // accepter promised ballot
+	outcomeAccepted  = "accepted"  // accepter accepted ballot
+	outcomeRejected  = "rejected"  // accepter promised, or accepted, a higher ballot, or granted another leader a lease
+	outcomeCompacted = "compacted" // accepter compacted slot into a snapshot
+	outcomeError     = "error"     // accepter was unreachable, or failed
+)
+
+/* Set provider of tracers for spans of every node; spans are dropped
+ * unless set.
+ */
+func SetTracerProvider(tp trace.TracerProvider) {
+	otel.SetTracerProvider(tp)
+}
+
+/* Return provider of tracers exporting spans of service to an OTLP/HTTP
+ * endpoint, such as http://localhost:4318/v1/traces, and as JSON to w.
+ * Either is left out if empty, or nil.
+ * Spans are exported in batches until provider is shut down.
+ */
+func NewTracerProvider(service, endpoint string, w io.Writer) (*sdktrace.TracerProvider, error) {
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", service))),
+	}
+	if endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		endpointOpts := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(endpoint)}
+		if u.Path == "" {
+			endpointOpts = append(endpointOpts, otlptracehttp.WithURLPath(otlpPath))
+		}
+		e, err := otlptracehttp.New(context.Background(), endpointOpts...)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(e))
+	}
+	if w != nil {
+		e, err := stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(e))
+	}
+	return sdktrace.NewTracerProvider(opts...), nil
+}
+
+/* Start span named name as child of any span in ctx.
+ * Return context of span, and span.
+ */
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+/* End span, with an error status if err is non-nil.
+ */
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+/* Return span attributes of slot and ballot b.
+ */
+func slotAttributes(slot int, b Ballot) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int("paxos.slot", slot),
+		attribute.String("paxos.ballot", b.String()),
+	}
+}
+
+/* Record on span reply p of accepter to proposal with ballot b in phase,
+ * or err if accepter failed to reply.
+ */
+func tracePromise(span trace.Span, phase string, b Ballot, p *Promise, err error) {
+	outcome := outcomePromised
+	if phase == phaseAccept {
+		outcome = outcomeAccepted
+	}
+	switch {
+	case err != nil:
+		outcome = outcomeError
+	case p.compacted():
+		outcome = outcomeCompacted
+	case p.Prepare.Greater(b):
+		outcome = outcomeRejected
+		span.SetAttributes(attribute.String("paxos.rejected_by", p.Prepare.String()))
+	case phase == phasePrepare && p.Lease != nil && p.Lease.Ballot.ID != b.ID:
+		outcome = outcomeRejected
+		span.SetAttributes(attribute.String("paxos.rejected_by", p.Lease.Ballot.String()))
+	case phase == phaseAccept && p.N.Greater(b):
+		outcome = outcomeRejected
+		span.SetAttributes(attribute.String("paxos.rejected_by", p.N.String()))
+	}
+	if err == nil && !p.N.IsZero() {
+		span.SetAttributes(attribute.String("paxos.accepted", p.N.String()))
+	}
+	span.SetAttributes(attribute.String("paxos.outcome", outcome))
+}
+
+/* Return context carrying trace context from headers of request.
+ * Cancellation of request is not carried, so messages to peers are not
+ * abandoned midway when a caller leaves.
+ */
+func requestContext(req *http.Request) context.Context {
+	return propagator.Extract(context.Background(), propagation.HeaderCarrier(req.Header))
+}
+
+/* Carrier of trace context in gRPC metadata. */
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+/* Return ctx with trace context of any span in ctx as outgoing gRPC metadata.
+ */
+func outgoingContext(ctx context.Context) context.Context {
+	md := metadata.MD{}
+	propagator.Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+/* Return context carrying trace context from incoming gRPC metadata of ctx,
+ * but not its cancellation, as with requestContext.
+ */
+func incomingContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return propagator.Extract(context.WithoutCancel(ctx), metadataCarrier(md))
+}
@@ -0,0 +1,323 @@
+package paxos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/marius-j-i/paxos/util"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+var (
+	/* Errors. */
+	errUnreachable = errors.New("member [%s] is unreachable")
+	errStatus      = errors.New("request [%s] responded [%s]")
+)
+
+/* Messages between network members.
+ * Nodes send every message to their peers through a transport, which either
+ * carries them over HTTP, or delivers them in-process.
+ */
+type Transport interface {
+	/* Proposer asks accepter at address to promise ballot b for slot; phase 1.
+	 * Return promise describing slot after accepter decided.
+	 * Trace context of ctx travels with message, as with Accept and Forward. */
+	Prepare(ctx context.Context, addr string, slot int, b Ballot) (*Promise, error)
+
+	/* Proposer asks accepter at address to accept ballot b with value v of kind k for slot; phase 2.
+	 * Return promise describing slot after accepter decided. */
+	Accept(ctx context.Context, addr string, slot int, b Ballot, k Kind, v []byte) (*Promise, error)
+
+	/* Accepter tells learner at address it accepted ballot b with value v of kind k for slot. */
+	Accepted(addr, from string, slot int, b Ballot, k Kind, v []byte) error
+
+	/* Proposer asks accepter at address for a lease with ballot b from slot onwards. */
+	Lease(addr, proposer string, slot int, b Ballot) (*Grant, error)
+
+	/* Proposer announces membership change v chosen for slot to member at address. */
+	Configure(addr string, slot int, v []byte) error
+
+	/* Return slot as accepted by accepter at address, to confirm a value chosen for it. */
+	Peek(addr string, slot int) (*Promise, error)
+
+	/* Proposer forwards proposal for value v of kind k to leader at address.
+	 * Return status code and proposal chosen, or error from leader,
+	 * or zero code if leader was unreachable. */
+	Forward(ctx context.Context, addr, from string, k Kind, v []byte) (int, *Proposal, error)
+
+	/* Return latest snapshot of member at address. */
+	Snapshot(addr string) (*Snapshot, error)
+
+	/* Return error unless member at address is alive. */
+	Ping(addr string) error
+
+	/* Client for HTTP requests to members from outside network, e.g. proposals. */
+	Client() *http.Client
+
+	/* Serve messages to node until Close is called for node. */
+	Serve(n *Node) error
+
+	/* Stop serving messages to node. */
+	Close(n *Node) error
+}
+
+/* Transport carrying messages between members as HTTP requests.
+ */
+type HttpTransport struct {
+	client    *http.Client            // client for requests to members
+	scheme    string                  // scheme of urls to members; http, or https with credentials
+	creds     *Credentials            // credentials for mutual TLS between members, if any
+	listeners map[string]net.Listener // address mapping to listener bound ahead of serving
+	mu        sync.Mutex              // guards listeners
+}
+
+/* Return new HTTP transport.
+ */
+func NewHttpTransport() *HttpTransport {
+	return &HttpTransport{
+		client:    &http.Client{},
+		scheme:    "http",
+		creds:     nil,
+		listeners: map[string]net.Listener{},
+	}
+}
+
+/* Return new HTTP transport, where members serve HTTPS and authenticate
+ * each other with certificates issued by cluster CA in credentials.
+ */
+func NewHttpTransportTLS(c *Credentials) *HttpTransport {
+	t := NewHttpTransport()
+	t.client.Transport = &http.Transport{TLSClientConfig: c.ClientConfig()}
+	t.scheme, t.creds = "https", c
+	return t
+}
+
+/* Return url to endpoint of member at address.
+ */
+func (t *HttpTransport) url(addr, endpoint string, args ...interface{}) string {
+	return util.Url(t.scheme, addr, endpoint, args...)
+}
+
+/* Bind a free port on host, and return address of listener.
+ * A node later served on the address uses the listener, so no other
+ * process can take the port in between.
+ */
+func (t *HttpTransport) Listen(host string) (string, error) {
+	l, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return ``, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	addr := l.Addr().String()
+	t.listeners[addr] = l
+	return addr, nil
+}
+
+/* POST value of kind k as body to url, with trace context of ctx in headers,
+ * and decode json-body of response into argument, if any.
+ */
+func (t *HttpTransport) post(ctx context.Context, url string, k Kind, v []byte, body interface{}) error {
+
+	req, err := http.NewRequestWithContext(ctx, POST, url, bytes.NewReader(v))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentTypeBytes)
+	if k != KindValue {
+		req.Header.Set(headerKind, kindNames[k])
+	}
+	propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return util.ErrorFormat(errStatus, url, resp.Status)
+	} else if body == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(body)
+}
+
+func (t *HttpTransport) Prepare(ctx context.Context, addr string, slot int, b Ballot) (*Promise, error) {
+	p := newPromise()
+	url := t.url(addr, "prepare", slot, b)
+	return p, t.post(ctx, url, KindValue, nil, p)
+}
+
+func (t *HttpTransport) Accept(ctx context.Context, addr string, slot int, b Ballot, k Kind, v []byte) (*Promise, error) {
+	p := newPromise()
+	url := t.url(addr, "accept", slot, b)
+	return p, t.post(ctx, url, k, v, p)
+}
+
+func (t *HttpTransport) Accepted(addr, from string, slot int, b Ballot, k Kind, v []byte) error {
+	url := t.url(addr, "learn", from, slot, b)
+	return t.post(context.Background(), url, k, v, nil)
+}
+
+func (t *HttpTransport) Lease(addr, proposer string, slot int, b Ballot) (*Grant, error) {
+	g := &Grant{}
+	url := t.url(addr, "lease", proposer, slot, b)
+	return g, t.post(context.Background(), url, KindValue, nil, g)
+}
+
+func (t *HttpTransport) Configure(addr string, slot int, v []byte) error {
+	url := t.url(addr, "configure", slot)
+	return t.post(context.Background(), url, KindValue, v, nil)
+}
+
+func (t *HttpTransport) Peek(addr string, slot int) (*Promise, error) {
+	url := t.url(addr, "accepted", slot)
+	resp, err := t.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, util.ErrorFormat(errStatus, url, resp.Status)
+	}
+	/* Slot-entry of /accepted/{slot}. */
+	entry := &struct {
+		Slot     int    `json:"slot"`
+		Accepted []byte `json:"accepted"`
+		Proposal Ballot `json:"proposal"`
+		Prepare  Ballot `json:"prepare"`
+		Kind     string `json:"kind"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(entry); err != nil {
+		return nil, err
+	}
+	k, err := parseKind(entry.Kind, url)
+	if err != nil {
+		return nil, err
+	}
+	p := newPromise()
+	p.From, p.Slot, p.N, p.Prepare, p.Value, p.Kind = addr, entry.Slot, entry.Proposal, entry.Prepare, entry.Accepted, k
+	return p, nil
+}
+
+/* Membership changes are forwarded to /members of leader, since /propose
+ * only proposes values.
+ */
+func (t *HttpTransport) Forward(ctx context.Context, addr, from string, k Kind, v []byte) (int, *Proposal, error) {
+
+	url := t.url(addr, "propose")
+	if k == KindChange {
+		ch, err := decodeChange(v)
+		if err != nil {
+			return 0, nil, err
+		}
+		url = t.url(addr, "members", ch.Addr, ch.roleName())
+	}
+	req, err := http.NewRequestWithContext(ctx, POST, url, bytes.NewReader(v))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set(headerForwarded, from)
+	propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	/* Error responses carry status text and message as body. */
+	if resp.StatusCode != http.StatusCreated {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, nil, err
+		}
+		_, msg, _ := strings.Cut(strings.TrimSpace(string(body)), "\n")
+		return resp.StatusCode, nil, errors.New(msg)
+	}
+	p := &Proposal{}
+	if err := json.NewDecoder(resp.Body).Decode(p); err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return resp.StatusCode, p, nil
+}
+
+func (t *HttpTransport) Snapshot(addr string) (*Snapshot, error) {
+	url := t.url(addr, GetSnapshot)
+	resp, err := t.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, util.ErrorFormat(errStatus, url, resp.Status)
+	}
+	snap := &Snapshot{}
+	return snap, json.NewDecoder(resp.Body).Decode(snap)
+}
+
+func (t *HttpTransport) Ping(addr string) error {
+	resp, err := t.client.Get(t.url(addr, GetAlive))
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (t *HttpTransport) Client() *http.Client {
+	return t.client
+}
+
+/* Serve node on listener bound by Listen, or bind its address otherwise.
+ * Serve HTTPS if transport has credentials.
+ */
+func (t *HttpTransport) Serve(n *Node) error {
+
+	t.mu.Lock()
+	l, ok := t.listeners[n.server.Addr]
+	delete(t.listeners, n.server.Addr)
+	t.mu.Unlock()
+
+	var err error
+	if !ok {
+		if l, err = net.Listen("tcp", n.server.Addr); err != nil {
+			return err
+		}
+	}
+	if t.creds != nil {
+		/* Certificate is given by configuration, not files. */
+		n.server.TLSConfig = t.creds.ServerConfig()
+		err = n.server.ServeTLS(l, ``, ``)
+	} else {
+		err = n.server.Serve(l)
+	}
+	if err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+/* Shutdown server of node gracefully within SHUTDOWNTIMEOUT.
+ */
+func (t *HttpTransport) Close(n *Node) error {
+
+	/* Servers wait on connections dialed but never used, unless closed by client. */
+	t.client.CloseIdleConnections()
+
+	/* Create context interface for server to use when shutting down. */
+	ctx, cancel := context.WithTimeout(context.Background(), SHUTDOWNTIMEOUT)
+	defer cancel()
+
+	return n.server.Shutdown(ctx)
+}
@@ -1,6 +1,7 @@
 package paxos
 
 import (
+	"io"
 	"net/http"
 	"strconv"
 	"time"
@@ -9,39 +10,82 @@ import (
 	"github.com/marius-j-i/paxos/util"
 )
 
-/* Response to /prepare from accepters.
+/* Response to /prepare and /accept from accepters.
  */
 type Promise struct {
-	from    string // url to response member
-	N       int    // accepted proposal number
-	prepare int    // promised proposal number
-	value   string // accepted proposal value
-	err     error  // non-nil if unsuccessful POST
+	From      string `json:"from"`      // url to response member
+	Slot      int    `json:"slot"`      // log index promise is for
+	N         Ballot `json:"N"`         // accepted proposal ballot
+	Prepare   Ballot `json:"prepare"`   // promised proposal ballot
+	Value     []byte `json:"value"`     // accepted proposal value
+	Kind      Kind   `json:"kind"`      // kind of accepted proposal value
+	Lease     *Lease `json:"lease"`     // valid lease accepter holds for a leader, if any
+	Compacted int    `json:"compacted"` // slot of latest snapshot of accepter; slots up to it are compacted
+	err       error  // non-nil if unsuccessful POST
 }
 
 /* Return a new promise instance.
  */
 func newPromise() *Promise {
 	return &Promise{
-		from:    ``,
-		N:       0,
-		prepare: 0,
-		value:   ``,
-		err:     nil,
+		From:      ``,
+		Slot:      0,
+		N:         Ballot{},
+		Prepare:   Ballot{},
+		Value:     nil,
+		Kind:      KindValue,
+		Lease:     nil,
+		Compacted: 0,
+		err:       nil,
 	}
 }
 
-/* Set promise members with nodes' members.
+/* Set promise members with nodes' members for argument slot s.
+ * Caller holds n.mu.
  */
-func (p *Promise) setNode(n *Node) *Promise {
-	p.from = n.server.Addr
-	p.N = n.N
-	p.prepare = n.prepare
-	p.value = n.value
+func (p *Promise) setNode(n *Node, s *Slot, slot int) *Promise {
+	p.From = n.server.Addr
+	p.Slot = slot
+	p.N = s.N
+	p.Prepare = s.Prepare
+	p.Value = s.Value
+	p.Kind = s.Kind
+	p.Lease = nil
+	if n.lease.valid() {
+		l := *n.lease
+		p.Lease = &l
+	}
+	p.Compacted = n.snapshotSlotLocked()
 	p.err = nil
 	return p
 }
 
+/* Return true if accepter refused promise, since slot is compacted into its
+ * latest snapshot. Value of slot was chosen, and is known from snapshot.
+ */
+func (p *Promise) compacted() bool {
+	return p.Slot <= p.Compacted
+}
+
+/* Return opaque value carried by request body, along with status code to
+ * respond with if body is empty or exceeds maxValueSize.
+ */
+func (n *Node) getBodyValue(w http.ResponseWriter, req *http.Request) ([]byte, int, error) {
+
+	v, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxValueSize))
+	if err != nil {
+		err := util.ErrorFormat(errValueSize, req.URL, maxValueSize)
+		return nil, http.StatusRequestEntityTooLarge, err
+
+	} else if len(v) == 0 {
+		err := util.ErrorFormat(errNoValue, req.URL, varValue)
+		return nil, http.StatusBadRequest, err
+
+	} else {
+		return v, http.StatusOK, nil
+	}
+}
+
 /* Return variable with mux regex name in url as string.
  */
 func (n *Node) getVarString(req *http.Request, name string) (string, error) {
@@ -71,6 +115,25 @@ func (n *Node) getVarInt(req *http.Request, name string) (int, error) {
 	}
 }
 
+/* Return variable with mux regex name in url parsed as ballot.
+ */
+func (n *Node) getVarBallot(req *http.Request, name string) (Ballot, error) {
+
+	if s, ok := mux.Vars(req)[name]; !ok {
+		err := util.ErrorFormat(errNoValue, req.URL, name)
+		return Ballot{}, err
+
+	} else {
+		return parseBallot(s)
+	}
+}
+
+/* Return kind of value named by header of request; a value, if none is named.
+ */
+func (n *Node) getHeaderKind(req *http.Request) (Kind, error) {
+	return parseKind(req.Header.Get(headerKind), req.URL.String())
+}
+
 /* Select a random timeout from interval to wait for; then return.
  */
 func (n *Node) timeout(lower, upper int, unit time.Duration) {
@@ -80,18 +143,57 @@ func (n *Node) timeout(lower, upper int, unit time.Duration) {
 /* Return string description of nodes' role.
  */
 func (n *Node) Role() string {
-	roles := map[Role]string{
-		Proposer: "proposer",
-		Accepter: "accepter",
-		Learner:  "learner",
+	return roleNames[n.currentRole()]
+}
+
+/* Return address node is served on.
+ */
+func (n *Node) Addr() string {
+	return n.server.Addr
+}
+
+/* Return role of node for next free slot; membership changes may change it.
+ */
+func (n *Node) currentRole() Role {
+	return n.config(n.nextSlot()).Role
+}
+
+/* Return true if node has argument role for next free slot.
+ */
+func (n *Node) is(r Role) bool {
+	return n.currentRole() == r
+}
+
+/* Return true if node has argument role in configuration of slot.
+ * Messages about a slot are handled by the role node has for that slot.
+ */
+func (n *Node) isAt(r Role, slot int) bool {
+	return n.config(slot).Role == r
+}
+
+/* Return network members for next free slot, without self.
+ * Network is replaced, never modified, on membership changes.
+ */
+func (n *Node) members() map[string]Role {
+	return n.config(n.nextSlot()).Network
+}
+
+/* Return role described by string, or
+ * return zero role if description is not a role.
+ */
+func parseRole(name string) Role {
+	for r, s := range roleNames {
+		if s == name {
+			return r
+		}
 	}
-	return roles[n.role]
+	return 0
 }
 
 /* Return number of members with argument role in network.
  */
 func (n *Node) LenRoles(r Role) (members int) {
-	for _, role := range n.network {
+	for _, role := range n.members() {
 		if role != r {
 			continue
 		}
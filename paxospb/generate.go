@@ -0,0 +1,6 @@
+/* Package paxospb holds the gRPC service definition of paxos nodes,
+ * and stubs generated from it.
+ */
+package paxospb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative paxos.proto
@@ -0,0 +1,1415 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: paxos.proto
+
+package paxospb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Kind of value chosen for a slot; values of clients are opaque.
+type Kind int32
+
+const (
+	Kind_VALUE  Kind = 0
+	Kind_CHANGE Kind = 1 // membership change, only proposed through ChangeMember
+)
+
+// Enum value maps for Kind.
+var (
+	Kind_name = map[int32]string{
+		0: "VALUE",
+		1: "CHANGE",
+	}
+	Kind_value = map[string]int32{
+		"VALUE":  0,
+		"CHANGE": 1,
+	}
+)
+
+func (x Kind) Enum() *Kind {
+	p := new(Kind)
+	*p = x
+	return p
+}
+
+func (x Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_paxos_proto_enumTypes[0].Descriptor()
+}
+
+func (Kind) Type() protoreflect.EnumType {
+	return &file_paxos_proto_enumTypes[0]
+}
+
+func (x Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Kind.Descriptor instead.
+func (Kind) EnumDescriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{0}
+}
+
+type Empty struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	mi := &file_paxos_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{0}
+}
+
+// Globally unique proposal number.
+type Ballot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Round         int64                  `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ballot) Reset() {
+	*x = Ballot{}
+	mi := &file_paxos_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ballot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ballot) ProtoMessage() {}
+
+func (x *Ballot) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ballot.ProtoReflect.Descriptor instead.
+func (*Ballot) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Ballot) GetRound() int64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *Ballot) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// Promise from an accepter to a leader for every slot from an index onwards.
+type Lease struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Leader        string                 `protobuf:"bytes,1,opt,name=leader,proto3" json:"leader,omitempty"`
+	Ballot        *Ballot                `protobuf:"bytes,2,opt,name=ballot,proto3" json:"ballot,omitempty"`
+	Slot          int64                  `protobuf:"varint,3,opt,name=slot,proto3" json:"slot,omitempty"`
+	Expires       int64                  `protobuf:"varint,4,opt,name=expires,proto3" json:"expires,omitempty"` // unix time in nanoseconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Lease) Reset() {
+	*x = Lease{}
+	mi := &file_paxos_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Lease) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Lease) ProtoMessage() {}
+
+func (x *Lease) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Lease.ProtoReflect.Descriptor instead.
+func (*Lease) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Lease) GetLeader() string {
+	if x != nil {
+		return x.Leader
+	}
+	return ""
+}
+
+func (x *Lease) GetBallot() *Ballot {
+	if x != nil {
+		return x.Ballot
+	}
+	return nil
+}
+
+func (x *Lease) GetSlot() int64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *Lease) GetExpires() int64 {
+	if x != nil {
+		return x.Expires
+	}
+	return 0
+}
+
+type PrepareRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slot          int64                  `protobuf:"varint,1,opt,name=slot,proto3" json:"slot,omitempty"`
+	Ballot        *Ballot                `protobuf:"bytes,2,opt,name=ballot,proto3" json:"ballot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrepareRequest) Reset() {
+	*x = PrepareRequest{}
+	mi := &file_paxos_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrepareRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrepareRequest) ProtoMessage() {}
+
+func (x *PrepareRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrepareRequest.ProtoReflect.Descriptor instead.
+func (*PrepareRequest) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PrepareRequest) GetSlot() int64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *PrepareRequest) GetBallot() *Ballot {
+	if x != nil {
+		return x.Ballot
+	}
+	return nil
+}
+
+type AcceptRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slot          int64                  `protobuf:"varint,1,opt,name=slot,proto3" json:"slot,omitempty"`
+	Ballot        *Ballot                `protobuf:"bytes,2,opt,name=ballot,proto3" json:"ballot,omitempty"`
+	Value         []byte                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Kind          Kind                   `protobuf:"varint,4,opt,name=kind,proto3,enum=paxos.Kind" json:"kind,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcceptRequest) Reset() {
+	*x = AcceptRequest{}
+	mi := &file_paxos_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcceptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcceptRequest) ProtoMessage() {}
+
+func (x *AcceptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcceptRequest.ProtoReflect.Descriptor instead.
+func (*AcceptRequest) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AcceptRequest) GetSlot() int64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *AcceptRequest) GetBallot() *Ballot {
+	if x != nil {
+		return x.Ballot
+	}
+	return nil
+}
+
+func (x *AcceptRequest) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *AcceptRequest) GetKind() Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return Kind_VALUE
+}
+
+// Response to prepare and accept from accepters.
+type Promise struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	Slot          int64                  `protobuf:"varint,2,opt,name=slot,proto3" json:"slot,omitempty"`
+	N             *Ballot                `protobuf:"bytes,3,opt,name=n,proto3" json:"n,omitempty"`
+	Prepare       *Ballot                `protobuf:"bytes,4,opt,name=prepare,proto3" json:"prepare,omitempty"`
+	Value         []byte                 `protobuf:"bytes,5,opt,name=value,proto3" json:"value,omitempty"`
+	Lease         *Lease                 `protobuf:"bytes,6,opt,name=lease,proto3" json:"lease,omitempty"`
+	Compacted     int64                  `protobuf:"varint,7,opt,name=compacted,proto3" json:"compacted,omitempty"` // slot of latest snapshot; slots up to it are compacted
+	Kind          Kind                   `protobuf:"varint,8,opt,name=kind,proto3,enum=paxos.Kind" json:"kind,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Promise) Reset() {
+	*x = Promise{}
+	mi := &file_paxos_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Promise) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Promise) ProtoMessage() {}
+
+func (x *Promise) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Promise.ProtoReflect.Descriptor instead.
+func (*Promise) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Promise) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *Promise) GetSlot() int64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *Promise) GetN() *Ballot {
+	if x != nil {
+		return x.N
+	}
+	return nil
+}
+
+func (x *Promise) GetPrepare() *Ballot {
+	if x != nil {
+		return x.Prepare
+	}
+	return nil
+}
+
+func (x *Promise) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *Promise) GetLease() *Lease {
+	if x != nil {
+		return x.Lease
+	}
+	return nil
+}
+
+func (x *Promise) GetCompacted() int64 {
+	if x != nil {
+		return x.Compacted
+	}
+	return 0
+}
+
+func (x *Promise) GetKind() Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return Kind_VALUE
+}
+
+type AcceptedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	Slot          int64                  `protobuf:"varint,2,opt,name=slot,proto3" json:"slot,omitempty"`
+	Ballot        *Ballot                `protobuf:"bytes,3,opt,name=ballot,proto3" json:"ballot,omitempty"`
+	Value         []byte                 `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	Kind          Kind                   `protobuf:"varint,5,opt,name=kind,proto3,enum=paxos.Kind" json:"kind,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcceptedRequest) Reset() {
+	*x = AcceptedRequest{}
+	mi := &file_paxos_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcceptedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcceptedRequest) ProtoMessage() {}
+
+func (x *AcceptedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcceptedRequest.ProtoReflect.Descriptor instead.
+func (*AcceptedRequest) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AcceptedRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *AcceptedRequest) GetSlot() int64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *AcceptedRequest) GetBallot() *Ballot {
+	if x != nil {
+		return x.Ballot
+	}
+	return nil
+}
+
+func (x *AcceptedRequest) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *AcceptedRequest) GetKind() Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return Kind_VALUE
+}
+
+type LeaseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Proposer      string                 `protobuf:"bytes,1,opt,name=proposer,proto3" json:"proposer,omitempty"`
+	Slot          int64                  `protobuf:"varint,2,opt,name=slot,proto3" json:"slot,omitempty"`
+	Ballot        *Ballot                `protobuf:"bytes,3,opt,name=ballot,proto3" json:"ballot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaseRequest) Reset() {
+	*x = LeaseRequest{}
+	mi := &file_paxos_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaseRequest) ProtoMessage() {}
+
+func (x *LeaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaseRequest.ProtoReflect.Descriptor instead.
+func (*LeaseRequest) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LeaseRequest) GetProposer() string {
+	if x != nil {
+		return x.Proposer
+	}
+	return ""
+}
+
+func (x *LeaseRequest) GetSlot() int64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *LeaseRequest) GetBallot() *Ballot {
+	if x != nil {
+		return x.Ballot
+	}
+	return nil
+}
+
+// Response to lease from accepters.
+type Grant struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	Lease         *Lease                 `protobuf:"bytes,2,opt,name=lease,proto3" json:"lease,omitempty"`
+	Prepare       *Ballot                `protobuf:"bytes,3,opt,name=prepare,proto3" json:"prepare,omitempty"`
+	Accepted      []*Promise             `protobuf:"bytes,4,rep,name=accepted,proto3" json:"accepted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Grant) Reset() {
+	*x = Grant{}
+	mi := &file_paxos_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Grant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Grant) ProtoMessage() {}
+
+func (x *Grant) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Grant.ProtoReflect.Descriptor instead.
+func (*Grant) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Grant) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *Grant) GetLease() *Lease {
+	if x != nil {
+		return x.Lease
+	}
+	return nil
+}
+
+func (x *Grant) GetPrepare() *Ballot {
+	if x != nil {
+		return x.Prepare
+	}
+	return nil
+}
+
+func (x *Grant) GetAccepted() []*Promise {
+	if x != nil {
+		return x.Accepted
+	}
+	return nil
+}
+
+// Application state after every value chosen up to and including slot.
+type Snapshot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slot          int64                  `protobuf:"varint,1,opt,name=slot,proto3" json:"slot,omitempty"`
+	State         []byte                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	Changes       map[int64]*Member      `protobuf:"bytes,3,rep,name=changes,proto3" json:"changes,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // membership changes chosen up to slot
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Snapshot) Reset() {
+	*x = Snapshot{}
+	mi := &file_paxos_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Snapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snapshot) ProtoMessage() {}
+
+func (x *Snapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snapshot.ProtoReflect.Descriptor instead.
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Snapshot) GetSlot() int64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *Snapshot) GetState() []byte {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+func (x *Snapshot) GetChanges() map[int64]*Member {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type ConfigureRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slot          int64                  `protobuf:"varint,1,opt,name=slot,proto3" json:"slot,omitempty"`
+	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigureRequest) Reset() {
+	*x = ConfigureRequest{}
+	mi := &file_paxos_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigureRequest) ProtoMessage() {}
+
+func (x *ConfigureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigureRequest.ProtoReflect.Descriptor instead.
+func (*ConfigureRequest) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ConfigureRequest) GetSlot() int64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *ConfigureRequest) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type ProposeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         []byte                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	ForwardedBy   string                 `protobuf:"bytes,2,opt,name=forwarded_by,json=forwardedBy,proto3" json:"forwarded_by,omitempty"` // proposer that forwarded proposal to leader, if any
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProposeRequest) Reset() {
+	*x = ProposeRequest{}
+	mi := &file_paxos_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProposeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProposeRequest) ProtoMessage() {}
+
+func (x *ProposeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProposeRequest.ProtoReflect.Descriptor instead.
+func (*ProposeRequest) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ProposeRequest) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *ProposeRequest) GetForwardedBy() string {
+	if x != nil {
+		return x.ForwardedBy
+	}
+	return ""
+}
+
+// Outcome of a proposal for a slot in log.
+type Proposal struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slot          int64                  `protobuf:"varint,1,opt,name=slot,proto3" json:"slot,omitempty"`
+	Ballot        *Ballot                `protobuf:"bytes,2,opt,name=ballot,proto3" json:"ballot,omitempty"`
+	Value         []byte                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Adopted       bool                   `protobuf:"varint,4,opt,name=adopted,proto3" json:"adopted,omitempty"` // true if slots were filled with adopted values before requested value was chosen
+	Kind          Kind                   `protobuf:"varint,5,opt,name=kind,proto3,enum=paxos.Kind" json:"kind,omitempty"`
+	Filled        []int64                `protobuf:"varint,6,rep,packed,name=filled,proto3" json:"filled,omitempty"` // slots filled with adopted values, in order, before slot of requested value
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Proposal) Reset() {
+	*x = Proposal{}
+	mi := &file_paxos_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Proposal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Proposal) ProtoMessage() {}
+
+func (x *Proposal) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Proposal.ProtoReflect.Descriptor instead.
+func (*Proposal) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Proposal) GetSlot() int64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *Proposal) GetBallot() *Ballot {
+	if x != nil {
+		return x.Ballot
+	}
+	return nil
+}
+
+func (x *Proposal) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *Proposal) GetAdopted() bool {
+	if x != nil {
+		return x.Adopted
+	}
+	return false
+}
+
+func (x *Proposal) GetKind() Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return Kind_VALUE
+}
+
+func (x *Proposal) GetFilled() []int64 {
+	if x != nil {
+		return x.Filled
+	}
+	return nil
+}
+
+type GetAcceptedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          int64                  `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            int64                  `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAcceptedRequest) Reset() {
+	*x = GetAcceptedRequest{}
+	mi := &file_paxos_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAcceptedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAcceptedRequest) ProtoMessage() {}
+
+func (x *GetAcceptedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAcceptedRequest.ProtoReflect.Descriptor instead.
+func (*GetAcceptedRequest) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetAcceptedRequest) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *GetAcceptedRequest) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+// Accepted value of a slot in log.
+type Entry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slot          int64                  `protobuf:"varint,1,opt,name=slot,proto3" json:"slot,omitempty"`
+	Accepted      []byte                 `protobuf:"bytes,2,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Proposal      *Ballot                `protobuf:"bytes,3,opt,name=proposal,proto3" json:"proposal,omitempty"`
+	Prepare       *Ballot                `protobuf:"bytes,4,opt,name=prepare,proto3" json:"prepare,omitempty"`
+	Kind          Kind                   `protobuf:"varint,5,opt,name=kind,proto3,enum=paxos.Kind" json:"kind,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Entry) Reset() {
+	*x = Entry{}
+	mi := &file_paxos_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Entry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entry) ProtoMessage() {}
+
+func (x *Entry) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entry.ProtoReflect.Descriptor instead.
+func (*Entry) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Entry) GetSlot() int64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *Entry) GetAccepted() []byte {
+	if x != nil {
+		return x.Accepted
+	}
+	return nil
+}
+
+func (x *Entry) GetProposal() *Ballot {
+	if x != nil {
+		return x.Proposal
+	}
+	return nil
+}
+
+func (x *Entry) GetPrepare() *Ballot {
+	if x != nil {
+		return x.Prepare
+	}
+	return nil
+}
+
+func (x *Entry) GetKind() Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return Kind_VALUE
+}
+
+type Member struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	Id            int64                  `protobuf:"varint,3,opt,name=id,proto3" json:"id,omitempty"` // unique id of member
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Member) Reset() {
+	*x = Member{}
+	mi := &file_paxos_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Member) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Member) ProtoMessage() {}
+
+func (x *Member) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Member.ProtoReflect.Descriptor instead.
+func (*Member) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Member) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *Member) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *Member) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type Members struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Members       []*Member              `protobuf:"bytes,1,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Members) Reset() {
+	*x = Members{}
+	mi := &file_paxos_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Members) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Members) ProtoMessage() {}
+
+func (x *Members) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Members.ProtoReflect.Descriptor instead.
+func (*Members) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Members) GetMembers() []*Member {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+type ChangeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Member        string                 `protobuf:"bytes,1,opt,name=member,proto3" json:"member,omitempty"`
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`                                  // proposer, accepter, learner, or none to remove member
+	ForwardedBy   string                 `protobuf:"bytes,3,opt,name=forwarded_by,json=forwardedBy,proto3" json:"forwarded_by,omitempty"` // proposer that forwarded change to leader, if any
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangeRequest) Reset() {
+	*x = ChangeRequest{}
+	mi := &file_paxos_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeRequest) ProtoMessage() {}
+
+func (x *ChangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paxos_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeRequest.ProtoReflect.Descriptor instead.
+func (*ChangeRequest) Descriptor() ([]byte, []int) {
+	return file_paxos_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ChangeRequest) GetMember() string {
+	if x != nil {
+		return x.Member
+	}
+	return ""
+}
+
+func (x *ChangeRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *ChangeRequest) GetForwardedBy() string {
+	if x != nil {
+		return x.ForwardedBy
+	}
+	return ""
+}
+
+var File_paxos_proto protoreflect.FileDescriptor
+
+const file_paxos_proto_rawDesc = "" +
+	"\n" +
+	"\vpaxos.proto\x12\x05paxos\"\a\n" +
+	"\x05Empty\".\n" +
+	"\x06Ballot\x12\x14\n" +
+	"\x05round\x18\x01 \x01(\x03R\x05round\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\"t\n" +
+	"\x05Lease\x12\x16\n" +
+	"\x06leader\x18\x01 \x01(\tR\x06leader\x12%\n" +
+	"\x06ballot\x18\x02 \x01(\v2\r.paxos.BallotR\x06ballot\x12\x12\n" +
+	"\x04slot\x18\x03 \x01(\x03R\x04slot\x12\x18\n" +
+	"\aexpires\x18\x04 \x01(\x03R\aexpires\"K\n" +
+	"\x0ePrepareRequest\x12\x12\n" +
+	"\x04slot\x18\x01 \x01(\x03R\x04slot\x12%\n" +
+	"\x06ballot\x18\x02 \x01(\v2\r.paxos.BallotR\x06ballot\"\x81\x01\n" +
+	"\rAcceptRequest\x12\x12\n" +
+	"\x04slot\x18\x01 \x01(\x03R\x04slot\x12%\n" +
+	"\x06ballot\x18\x02 \x01(\v2\r.paxos.BallotR\x06ballot\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\fR\x05value\x12\x1f\n" +
+	"\x04kind\x18\x04 \x01(\x0e2\v.paxos.KindR\x04kind\"\xf0\x01\n" +
+	"\aPromise\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x12\n" +
+	"\x04slot\x18\x02 \x01(\x03R\x04slot\x12\x1b\n" +
+	"\x01n\x18\x03 \x01(\v2\r.paxos.BallotR\x01n\x12'\n" +
+	"\aprepare\x18\x04 \x01(\v2\r.paxos.BallotR\aprepare\x12\x14\n" +
+	"\x05value\x18\x05 \x01(\fR\x05value\x12\"\n" +
+	"\x05lease\x18\x06 \x01(\v2\f.paxos.LeaseR\x05lease\x12\x1c\n" +
+	"\tcompacted\x18\a \x01(\x03R\tcompacted\x12\x1f\n" +
+	"\x04kind\x18\b \x01(\x0e2\v.paxos.KindR\x04kind\"\x97\x01\n" +
+	"\x0fAcceptedRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x12\n" +
+	"\x04slot\x18\x02 \x01(\x03R\x04slot\x12%\n" +
+	"\x06ballot\x18\x03 \x01(\v2\r.paxos.BallotR\x06ballot\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\fR\x05value\x12\x1f\n" +
+	"\x04kind\x18\x05 \x01(\x0e2\v.paxos.KindR\x04kind\"e\n" +
+	"\fLeaseRequest\x12\x1a\n" +
+	"\bproposer\x18\x01 \x01(\tR\bproposer\x12\x12\n" +
+	"\x04slot\x18\x02 \x01(\x03R\x04slot\x12%\n" +
+	"\x06ballot\x18\x03 \x01(\v2\r.paxos.BallotR\x06ballot\"\x94\x01\n" +
+	"\x05Grant\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\"\n" +
+	"\x05lease\x18\x02 \x01(\v2\f.paxos.LeaseR\x05lease\x12'\n" +
+	"\aprepare\x18\x03 \x01(\v2\r.paxos.BallotR\aprepare\x12*\n" +
+	"\baccepted\x18\x04 \x03(\v2\x0e.paxos.PromiseR\baccepted\"\xb7\x01\n" +
+	"\bSnapshot\x12\x12\n" +
+	"\x04slot\x18\x01 \x01(\x03R\x04slot\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\fR\x05state\x126\n" +
+	"\achanges\x18\x03 \x03(\v2\x1c.paxos.Snapshot.ChangesEntryR\achanges\x1aI\n" +
+	"\fChangesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x03R\x03key\x12#\n" +
+	"\x05value\x18\x02 \x01(\v2\r.paxos.MemberR\x05value:\x028\x01\"<\n" +
+	"\x10ConfigureRequest\x12\x12\n" +
+	"\x04slot\x18\x01 \x01(\x03R\x04slot\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\"I\n" +
+	"\x0eProposeRequest\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\fR\x05value\x12!\n" +
+	"\fforwarded_by\x18\x02 \x01(\tR\vforwardedBy\"\xae\x01\n" +
+	"\bProposal\x12\x12\n" +
+	"\x04slot\x18\x01 \x01(\x03R\x04slot\x12%\n" +
+	"\x06ballot\x18\x02 \x01(\v2\r.paxos.BallotR\x06ballot\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\fR\x05value\x12\x18\n" +
+	"\aadopted\x18\x04 \x01(\bR\aadopted\x12\x1f\n" +
+	"\x04kind\x18\x05 \x01(\x0e2\v.paxos.KindR\x04kind\x12\x16\n" +
+	"\x06filled\x18\x06 \x03(\x03R\x06filled\"8\n" +
+	"\x12GetAcceptedRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\x03R\x02to\"\xac\x01\n" +
+	"\x05Entry\x12\x12\n" +
+	"\x04slot\x18\x01 \x01(\x03R\x04slot\x12\x1a\n" +
+	"\baccepted\x18\x02 \x01(\fR\baccepted\x12)\n" +
+	"\bproposal\x18\x03 \x01(\v2\r.paxos.BallotR\bproposal\x12'\n" +
+	"\aprepare\x18\x04 \x01(\v2\r.paxos.BallotR\aprepare\x12\x1f\n" +
+	"\x04kind\x18\x05 \x01(\x0e2\v.paxos.KindR\x04kind\"@\n" +
+	"\x06Member\x12\x12\n" +
+	"\x04addr\x18\x01 \x01(\tR\x04addr\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\x03R\x02id\"2\n" +
+	"\aMembers\x12'\n" +
+	"\amembers\x18\x01 \x03(\v2\r.paxos.MemberR\amembers\"^\n" +
+	"\rChangeRequest\x12\x16\n" +
+	"\x06member\x18\x01 \x01(\tR\x06member\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\x12!\n" +
+	"\fforwarded_by\x18\x03 \x01(\tR\vforwardedBy*\x1d\n" +
+	"\x04Kind\x12\t\n" +
+	"\x05VALUE\x10\x00\x12\n" +
+	"\n" +
+	"\x06CHANGE\x10\x012\xda\x04\n" +
+	"\x05Paxos\x120\n" +
+	"\aPrepare\x12\x15.paxos.PrepareRequest\x1a\x0e.paxos.Promise\x12.\n" +
+	"\x06Accept\x12\x14.paxos.AcceptRequest\x1a\x0e.paxos.Promise\x120\n" +
+	"\bAccepted\x12\x16.paxos.AcceptedRequest\x1a\f.paxos.Empty\x12*\n" +
+	"\x05Lease\x12\x13.paxos.LeaseRequest\x1a\f.paxos.Grant\x122\n" +
+	"\tConfigure\x12\x17.paxos.ConfigureRequest\x1a\f.paxos.Empty\x12\"\n" +
+	"\x04Ping\x12\f.paxos.Empty\x1a\f.paxos.Empty\x12,\n" +
+	"\vGetSnapshot\x12\f.paxos.Empty\x1a\x0f.paxos.Snapshot\x121\n" +
+	"\aPropose\x12\x15.paxos.ProposeRequest\x1a\x0f.paxos.Proposal\x12;\n" +
+	"\rProposeStream\x12\x15.paxos.ProposeRequest\x1a\x0f.paxos.Proposal(\x010\x01\x128\n" +
+	"\vGetAccepted\x12\x19.paxos.GetAcceptedRequest\x1a\f.paxos.Entry0\x01\x12*\n" +
+	"\n" +
+	"GetMembers\x12\f.paxos.Empty\x1a\x0e.paxos.Members\x125\n" +
+	"\fChangeMember\x12\x14.paxos.ChangeRequest\x1a\x0f.paxos.ProposalB%Z#github.com/marius-j-i/paxos/paxospbb\x06proto3"
+
+var (
+	file_paxos_proto_rawDescOnce sync.Once
+	file_paxos_proto_rawDescData []byte
+)
+
+func file_paxos_proto_rawDescGZIP() []byte {
+	file_paxos_proto_rawDescOnce.Do(func() {
+		file_paxos_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_paxos_proto_rawDesc), len(file_paxos_proto_rawDesc)))
+	})
+	return file_paxos_proto_rawDescData
+}
+
+var file_paxos_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_paxos_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_paxos_proto_goTypes = []any{
+	(Kind)(0),                  // 0: paxos.Kind
+	(*Empty)(nil),              // 1: paxos.Empty
+	(*Ballot)(nil),             // 2: paxos.Ballot
+	(*Lease)(nil),              // 3: paxos.Lease
+	(*PrepareRequest)(nil),     // 4: paxos.PrepareRequest
+	(*AcceptRequest)(nil),      // 5: paxos.AcceptRequest
+	(*Promise)(nil),            // 6: paxos.Promise
+	(*AcceptedRequest)(nil),    // 7: paxos.AcceptedRequest
+	(*LeaseRequest)(nil),       // 8: paxos.LeaseRequest
+	(*Grant)(nil),              // 9: paxos.Grant
+	(*Snapshot)(nil),           // 10: paxos.Snapshot
+	(*ConfigureRequest)(nil),   // 11: paxos.ConfigureRequest
+	(*ProposeRequest)(nil),     // 12: paxos.ProposeRequest
+	(*Proposal)(nil),           // 13: paxos.Proposal
+	(*GetAcceptedRequest)(nil), // 14: paxos.GetAcceptedRequest
+	(*Entry)(nil),              // 15: paxos.Entry
+	(*Member)(nil),             // 16: paxos.Member
+	(*Members)(nil),            // 17: paxos.Members
+	(*ChangeRequest)(nil),      // 18: paxos.ChangeRequest
+	nil,                        // 19: paxos.Snapshot.ChangesEntry
+}
+var file_paxos_proto_depIdxs = []int32{
+	2,  // 0: paxos.Lease.ballot:type_name -> paxos.Ballot
+	2,  // 1: paxos.PrepareRequest.ballot:type_name -> paxos.Ballot
+	2,  // 2: paxos.AcceptRequest.ballot:type_name -> paxos.Ballot
+	0,  // 3: paxos.AcceptRequest.kind:type_name -> paxos.Kind
+	2,  // 4: paxos.Promise.n:type_name -> paxos.Ballot
+	2,  // 5: paxos.Promise.prepare:type_name -> paxos.Ballot
+	3,  // 6: paxos.Promise.lease:type_name -> paxos.Lease
+	0,  // 7: paxos.Promise.kind:type_name -> paxos.Kind
+	2,  // 8: paxos.AcceptedRequest.ballot:type_name -> paxos.Ballot
+	0,  // 9: paxos.AcceptedRequest.kind:type_name -> paxos.Kind
+	2,  // 10: paxos.LeaseRequest.ballot:type_name -> paxos.Ballot
+	3,  // 11: paxos.Grant.lease:type_name -> paxos.Lease
+	2,  // 12: paxos.Grant.prepare:type_name -> paxos.Ballot
+	6,  // 13: paxos.Grant.accepted:type_name -> paxos.Promise
+	19, // 14: paxos.Snapshot.changes:type_name -> paxos.Snapshot.ChangesEntry
+	2,  // 15: paxos.Proposal.ballot:type_name -> paxos.Ballot
+	0,  // 16: paxos.Proposal.kind:type_name -> paxos.Kind
+	2,  // 17: paxos.Entry.proposal:type_name -> paxos.Ballot
+	2,  // 18: paxos.Entry.prepare:type_name -> paxos.Ballot
+	0,  // 19: paxos.Entry.kind:type_name -> paxos.Kind
+	16, // 20: paxos.Members.members:type_name -> paxos.Member
+	16, // 21: paxos.Snapshot.ChangesEntry.value:type_name -> paxos.Member
+	4,  // 22: paxos.Paxos.Prepare:input_type -> paxos.PrepareRequest
+	5,  // 23: paxos.Paxos.Accept:input_type -> paxos.AcceptRequest
+	7,  // 24: paxos.Paxos.Accepted:input_type -> paxos.AcceptedRequest
+	8,  // 25: paxos.Paxos.Lease:input_type -> paxos.LeaseRequest
+	11, // 26: paxos.Paxos.Configure:input_type -> paxos.ConfigureRequest
+	1,  // 27: paxos.Paxos.Ping:input_type -> paxos.Empty
+	1,  // 28: paxos.Paxos.GetSnapshot:input_type -> paxos.Empty
+	12, // 29: paxos.Paxos.Propose:input_type -> paxos.ProposeRequest
+	12, // 30: paxos.Paxos.ProposeStream:input_type -> paxos.ProposeRequest
+	14, // 31: paxos.Paxos.GetAccepted:input_type -> paxos.GetAcceptedRequest
+	1,  // 32: paxos.Paxos.GetMembers:input_type -> paxos.Empty
+	18, // 33: paxos.Paxos.ChangeMember:input_type -> paxos.ChangeRequest
+	6,  // 34: paxos.Paxos.Prepare:output_type -> paxos.Promise
+	6,  // 35: paxos.Paxos.Accept:output_type -> paxos.Promise
+	1,  // 36: paxos.Paxos.Accepted:output_type -> paxos.Empty
+	9,  // 37: paxos.Paxos.Lease:output_type -> paxos.Grant
+	1,  // 38: paxos.Paxos.Configure:output_type -> paxos.Empty
+	1,  // 39: paxos.Paxos.Ping:output_type -> paxos.Empty
+	10, // 40: paxos.Paxos.GetSnapshot:output_type -> paxos.Snapshot
+	13, // 41: paxos.Paxos.Propose:output_type -> paxos.Proposal
+	13, // 42: paxos.Paxos.ProposeStream:output_type -> paxos.Proposal
+	15, // 43: paxos.Paxos.GetAccepted:output_type -> paxos.Entry
+	17, // 44: paxos.Paxos.GetMembers:output_type -> paxos.Members
+	13, // 45: paxos.Paxos.ChangeMember:output_type -> paxos.Proposal
+	34, // [34:46] is the sub-list for method output_type
+	22, // [22:34] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
+}
+
+func init() { file_paxos_proto_init() }
+func file_paxos_proto_init() {
+	if File_paxos_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_paxos_proto_rawDesc), len(file_paxos_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_paxos_proto_goTypes,
+		DependencyIndexes: file_paxos_proto_depIdxs,
+		EnumInfos:         file_paxos_proto_enumTypes,
+		MessageInfos:      file_paxos_proto_msgTypes,
+	}.Build()
+	File_paxos_proto = out.File
+	file_paxos_proto_goTypes = nil
+	file_paxos_proto_depIdxs = nil
+}
@@ -0,0 +1,567 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: paxos.proto
+
+package paxospb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Paxos_Prepare_FullMethodName       = "/paxos.Paxos/Prepare"
+	Paxos_Accept_FullMethodName        = "/paxos.Paxos/Accept"
+	Paxos_Accepted_FullMethodName      = "/paxos.Paxos/Accepted"
+	Paxos_Lease_FullMethodName         = "/paxos.Paxos/Lease"
+	Paxos_Configure_FullMethodName     = "/paxos.Paxos/Configure"
+	Paxos_Ping_FullMethodName          = "/paxos.Paxos/Ping"
+	Paxos_GetSnapshot_FullMethodName   = "/paxos.Paxos/GetSnapshot"
+	Paxos_Propose_FullMethodName       = "/paxos.Paxos/Propose"
+	Paxos_ProposeStream_FullMethodName = "/paxos.Paxos/ProposeStream"
+	Paxos_GetAccepted_FullMethodName   = "/paxos.Paxos/GetAccepted"
+	Paxos_GetMembers_FullMethodName    = "/paxos.Paxos/GetMembers"
+	Paxos_ChangeMember_FullMethodName  = "/paxos.Paxos/ChangeMember"
+)
+
+// PaxosClient is the client API for Paxos service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Peer messages between network members, and client API of proposers.
+// Mirrors the HTTP/JSON API; nodes of a cluster serve either.
+type PaxosClient interface {
+	// Proposer asks accepter to promise ballot for slot; phase 1.
+	Prepare(ctx context.Context, in *PrepareRequest, opts ...grpc.CallOption) (*Promise, error)
+	// Proposer asks accepter to accept ballot with value for slot; phase 2.
+	Accept(ctx context.Context, in *AcceptRequest, opts ...grpc.CallOption) (*Promise, error)
+	// Accepter tells learner it accepted ballot with value for slot.
+	Accepted(ctx context.Context, in *AcceptedRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Proposer asks accepter for a lease from slot onwards.
+	Lease(ctx context.Context, in *LeaseRequest, opts ...grpc.CallOption) (*Grant, error)
+	// Proposer announces membership change chosen for slot.
+	Configure(ctx context.Context, in *ConfigureRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Responds if member is alive.
+	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	// Return latest snapshot of member, for lagging members to catch up from.
+	GetSnapshot(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Snapshot, error)
+	// Propose value until chosen for a slot.
+	Propose(ctx context.Context, in *ProposeRequest, opts ...grpc.CallOption) (*Proposal, error)
+	// Propose each value streamed, in order, and stream back proposals chosen.
+	ProposeStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ProposeRequest, Proposal], error)
+	// Stream accepted values of slots within [from, to].
+	GetAccepted(ctx context.Context, in *GetAcceptedRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Entry], error)
+	// Return current network members and their roles, including self.
+	GetMembers(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Members, error)
+	// Change role of member through consensus.
+	ChangeMember(ctx context.Context, in *ChangeRequest, opts ...grpc.CallOption) (*Proposal, error)
+}
+
+type paxosClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPaxosClient(cc grpc.ClientConnInterface) PaxosClient {
+	return &paxosClient{cc}
+}
+
+func (c *paxosClient) Prepare(ctx context.Context, in *PrepareRequest, opts ...grpc.CallOption) (*Promise, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Promise)
+	err := c.cc.Invoke(ctx, Paxos_Prepare_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paxosClient) Accept(ctx context.Context, in *AcceptRequest, opts ...grpc.CallOption) (*Promise, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Promise)
+	err := c.cc.Invoke(ctx, Paxos_Accept_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paxosClient) Accepted(ctx context.Context, in *AcceptedRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Paxos_Accepted_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paxosClient) Lease(ctx context.Context, in *LeaseRequest, opts ...grpc.CallOption) (*Grant, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Grant)
+	err := c.cc.Invoke(ctx, Paxos_Lease_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paxosClient) Configure(ctx context.Context, in *ConfigureRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Paxos_Configure_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paxosClient) Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Paxos_Ping_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paxosClient) GetSnapshot(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Snapshot, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Snapshot)
+	err := c.cc.Invoke(ctx, Paxos_GetSnapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paxosClient) Propose(ctx context.Context, in *ProposeRequest, opts ...grpc.CallOption) (*Proposal, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Proposal)
+	err := c.cc.Invoke(ctx, Paxos_Propose_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paxosClient) ProposeStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ProposeRequest, Proposal], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Paxos_ServiceDesc.Streams[0], Paxos_ProposeStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ProposeRequest, Proposal]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Paxos_ProposeStreamClient = grpc.BidiStreamingClient[ProposeRequest, Proposal]
+
+func (c *paxosClient) GetAccepted(ctx context.Context, in *GetAcceptedRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Entry], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Paxos_ServiceDesc.Streams[1], Paxos_GetAccepted_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetAcceptedRequest, Entry]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Paxos_GetAcceptedClient = grpc.ServerStreamingClient[Entry]
+
+func (c *paxosClient) GetMembers(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Members, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Members)
+	err := c.cc.Invoke(ctx, Paxos_GetMembers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paxosClient) ChangeMember(ctx context.Context, in *ChangeRequest, opts ...grpc.CallOption) (*Proposal, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Proposal)
+	err := c.cc.Invoke(ctx, Paxos_ChangeMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PaxosServer is the server API for Paxos service.
+// All implementations must embed UnimplementedPaxosServer
+// for forward compatibility.
+//
+// Peer messages between network members, and client API of proposers.
+// Mirrors the HTTP/JSON API; nodes of a cluster serve either.
+type PaxosServer interface {
+	// Proposer asks accepter to promise ballot for slot; phase 1.
+	Prepare(context.Context, *PrepareRequest) (*Promise, error)
+	// Proposer asks accepter to accept ballot with value for slot; phase 2.
+	Accept(context.Context, *AcceptRequest) (*Promise, error)
+	// Accepter tells learner it accepted ballot with value for slot.
+	Accepted(context.Context, *AcceptedRequest) (*Empty, error)
+	// Proposer asks accepter for a lease from slot onwards.
+	Lease(context.Context, *LeaseRequest) (*Grant, error)
+	// Proposer announces membership change chosen for slot.
+	Configure(context.Context, *ConfigureRequest) (*Empty, error)
+	// Responds if member is alive.
+	Ping(context.Context, *Empty) (*Empty, error)
+	// Return latest snapshot of member, for lagging members to catch up from.
+	GetSnapshot(context.Context, *Empty) (*Snapshot, error)
+	// Propose value until chosen for a slot.
+	Propose(context.Context, *ProposeRequest) (*Proposal, error)
+	// Propose each value streamed, in order, and stream back proposals chosen.
+	ProposeStream(grpc.BidiStreamingServer[ProposeRequest, Proposal]) error
+	// Stream accepted values of slots within [from, to].
+	GetAccepted(*GetAcceptedRequest, grpc.ServerStreamingServer[Entry]) error
+	// Return current network members and their roles, including self.
+	GetMembers(context.Context, *Empty) (*Members, error)
+	// Change role of member through consensus.
+	ChangeMember(context.Context, *ChangeRequest) (*Proposal, error)
+	mustEmbedUnimplementedPaxosServer()
+}
+
+// UnimplementedPaxosServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPaxosServer struct{}
+
+func (UnimplementedPaxosServer) Prepare(context.Context, *PrepareRequest) (*Promise, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Prepare not implemented")
+}
+func (UnimplementedPaxosServer) Accept(context.Context, *AcceptRequest) (*Promise, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Accept not implemented")
+}
+func (UnimplementedPaxosServer) Accepted(context.Context, *AcceptedRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Accepted not implemented")
+}
+func (UnimplementedPaxosServer) Lease(context.Context, *LeaseRequest) (*Grant, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Lease not implemented")
+}
+func (UnimplementedPaxosServer) Configure(context.Context, *ConfigureRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Configure not implemented")
+}
+func (UnimplementedPaxosServer) Ping(context.Context, *Empty) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
+}
+func (UnimplementedPaxosServer) GetSnapshot(context.Context, *Empty) (*Snapshot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSnapshot not implemented")
+}
+func (UnimplementedPaxosServer) Propose(context.Context, *ProposeRequest) (*Proposal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Propose not implemented")
+}
+func (UnimplementedPaxosServer) ProposeStream(grpc.BidiStreamingServer[ProposeRequest, Proposal]) error {
+	return status.Errorf(codes.Unimplemented, "method ProposeStream not implemented")
+}
+func (UnimplementedPaxosServer) GetAccepted(*GetAcceptedRequest, grpc.ServerStreamingServer[Entry]) error {
+	return status.Errorf(codes.Unimplemented, "method GetAccepted not implemented")
+}
+func (UnimplementedPaxosServer) GetMembers(context.Context, *Empty) (*Members, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMembers not implemented")
+}
+func (UnimplementedPaxosServer) ChangeMember(context.Context, *ChangeRequest) (*Proposal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeMember not implemented")
+}
+func (UnimplementedPaxosServer) mustEmbedUnimplementedPaxosServer() {}
+func (UnimplementedPaxosServer) testEmbeddedByValue()               {}
+
+// UnsafePaxosServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PaxosServer will
+// result in compilation errors.
+type UnsafePaxosServer interface {
+	mustEmbedUnimplementedPaxosServer()
+}
+
+func RegisterPaxosServer(s grpc.ServiceRegistrar, srv PaxosServer) {
+	// If the following call pancis, it indicates UnimplementedPaxosServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Paxos_ServiceDesc, srv)
+}
+
+func _Paxos_Prepare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrepareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaxosServer).Prepare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Paxos_Prepare_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaxosServer).Prepare(ctx, req.(*PrepareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Paxos_Accept_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AcceptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaxosServer).Accept(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Paxos_Accept_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaxosServer).Accept(ctx, req.(*AcceptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Paxos_Accepted_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AcceptedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaxosServer).Accepted(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Paxos_Accepted_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaxosServer).Accepted(ctx, req.(*AcceptedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Paxos_Lease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaxosServer).Lease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Paxos_Lease_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaxosServer).Lease(ctx, req.(*LeaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Paxos_Configure_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaxosServer).Configure(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Paxos_Configure_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaxosServer).Configure(ctx, req.(*ConfigureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Paxos_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaxosServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Paxos_Ping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaxosServer).Ping(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Paxos_GetSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaxosServer).GetSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Paxos_GetSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaxosServer).GetSnapshot(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Paxos_Propose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProposeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaxosServer).Propose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Paxos_Propose_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaxosServer).Propose(ctx, req.(*ProposeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Paxos_ProposeStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PaxosServer).ProposeStream(&grpc.GenericServerStream[ProposeRequest, Proposal]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Paxos_ProposeStreamServer = grpc.BidiStreamingServer[ProposeRequest, Proposal]
+
+func _Paxos_GetAccepted_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetAcceptedRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PaxosServer).GetAccepted(m, &grpc.GenericServerStream[GetAcceptedRequest, Entry]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Paxos_GetAcceptedServer = grpc.ServerStreamingServer[Entry]
+
+func _Paxos_GetMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaxosServer).GetMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Paxos_GetMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaxosServer).GetMembers(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Paxos_ChangeMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaxosServer).ChangeMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Paxos_ChangeMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaxosServer).ChangeMember(ctx, req.(*ChangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Paxos_ServiceDesc is the grpc.ServiceDesc for Paxos service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Paxos_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "paxos.Paxos",
+	HandlerType: (*PaxosServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Prepare",
+			Handler:    _Paxos_Prepare_Handler,
+		},
+		{
+			MethodName: "Accept",
+			Handler:    _Paxos_Accept_Handler,
+		},
+		{
+			MethodName: "Accepted",
+			Handler:    _Paxos_Accepted_Handler,
+		},
+		{
+			MethodName: "Lease",
+			Handler:    _Paxos_Lease_Handler,
+		},
+		{
+			MethodName: "Configure",
+			Handler:    _Paxos_Configure_Handler,
+		},
+		{
+			MethodName: "Ping",
+			Handler:    _Paxos_Ping_Handler,
+		},
+		{
+			MethodName: "GetSnapshot",
+			Handler:    _Paxos_GetSnapshot_Handler,
+		},
+		{
+			MethodName: "Propose",
+			Handler:    _Paxos_Propose_Handler,
+		},
+		{
+			MethodName: "GetMembers",
+			Handler:    _Paxos_GetMembers_Handler,
+		},
+		{
+			MethodName: "ChangeMember",
+			Handler:    _Paxos_ChangeMember_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ProposeStream",
+			Handler:       _Paxos_ProposeStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "GetAccepted",
+			Handler:       _Paxos_GetAccepted_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "paxos.proto",
+}
@@ -21,7 +21,13 @@ func ErrorFormat(err error, formats ...interface{}) error {
 /* Return format `http://<addr>/<endpoint>/args...`
  */
 func HttpUrl(addr, endpoint string, args ...interface{}) string {
-	url := fmt.Sprintf("http://%s/%s", addr, endpoint)
+	return Url("http", addr, endpoint, args...)
+}
+
+/* Return format `<scheme>://<addr>/<endpoint>/args...`
+ */
+func Url(scheme, addr, endpoint string, args ...interface{}) string {
+	url := fmt.Sprintf("%s://%s/%s", scheme, addr, endpoint)
 	for _, a := range args {
 		url = fmt.Sprintf("%s/%v", url, a)
 	}